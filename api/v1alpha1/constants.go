@@ -19,6 +19,54 @@ package v1alpha1
 
 type MongoDBPhase string
 
+// TerminationPolicy controls the resources kept when the instance is deleted
+// +kubebuilder:validation:Enum=DoNotTerminate;Halt;Delete;WipeOut
+type TerminationPolicy string
+
+const (
+	// TerminationPolicyDoNotTerminate blocks the deletion of the instance
+	TerminationPolicyDoNotTerminate TerminationPolicy = "DoNotTerminate"
+	// TerminationPolicyHalt keeps the volumes and the secret
+	TerminationPolicyHalt TerminationPolicy = "Halt"
+	// TerminationPolicyDelete removes the volumes and keeps the secret
+	TerminationPolicyDelete TerminationPolicy = "Delete"
+	// TerminationPolicyWipeOut removes the volumes and the secret
+	TerminationPolicyWipeOut TerminationPolicy = "WipeOut"
+)
+
+// Flavor is the distribution of the MongoDB image
+// +kubebuilder:validation:Enum=Bitnami;Official;Percona
+type Flavor string
+
+const (
+	// FlavorBitnami runs the bitnami/mongodb image
+	FlavorBitnami Flavor = "Bitnami"
+	// FlavorOfficial runs the official mongo image
+	FlavorOfficial Flavor = "Official"
+	// FlavorPercona runs the Percona Server for MongoDB image
+	FlavorPercona Flavor = "Percona"
+)
+
+// AdoptionPolicy defines whether a user existing in the database is taken over
+// +kubebuilder:validation:Enum=Fail;Adopt;AdoptIfNoOwner
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyFail rejects the users handled by another resource or created by hand
+	AdoptionPolicyFail AdoptionPolicy = "Fail"
+	// AdoptionPolicyAdopt takes over the user whatever its owner
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyAdoptIfNoOwner takes over the users created by hand or whose resource does not exist anymore
+	AdoptionPolicyAdoptIfNoOwner AdoptionPolicy = "AdoptIfNoOwner"
+)
+
+const (
+	// ClientSourceFromPodCIDR allows the pod CIDR of the cluster, read from the nodes unless set in the operator configuration
+	ClientSourceFromPodCIDR ClientSourceFrom = "PodCIDR"
+	// ClientSourceFromNamespacePods allows the IP addresses of the pods running in the namespace of the user
+	ClientSourceFromNamespacePods ClientSourceFrom = "NamespacePods"
+)
+
 const (
 	// Database
 	MongoDBPort                           = 27017
@@ -29,7 +77,55 @@ const (
 	MongoDBPhaseNotReady     MongoDBPhase = "NotReady"
 	MongoDBPhasePaused       MongoDBPhase = "Paused"
 
+	// MongoDBMetricsUsername is the clusterMonitor user used by the metrics exporter
+	MongoDBMetricsUsername = "mongodb-exporter"
+
+	// MongoDBReplicaSetName is the name of the replica set
+	MongoDBReplicaSetName = "rs0"
+
+	// MongoDBMaxVotingMembers is the maximum number of voting members in a replica set
+	MongoDBMaxVotingMembers = 7
+
+	// DefaultStorageClassAnnotation marks the default storage class of the cluster
+	DefaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+	// ConditionVolumeExpansion reports the progress of the volume expansion
+	ConditionVolumeExpansion = "VolumeExpansion"
+
+	// ConditionHibernated reports whether the instance is scaled to zero by its hibernation schedules
+	ConditionHibernated = "Hibernated"
+
+	// ConditionExpiring reports that the time to live of the instance is about to elapse
+	ConditionExpiring = "Expiring"
+
+	// ConditionReplicaSetHealthy reports whether the replica set has a primary and all its members are reachable
+	ConditionReplicaSetHealthy = "ReplicaSetHealthy"
+
+	// ConditionReplicationLagging reports whether a secondary lags behind the primary
+	ConditionReplicationLagging = "ReplicationLagging"
+
+	// ConditionRestored reports that the replica set configuration restored from the data source was replaced
+	ConditionRestored = "Restored"
+
+	// ConditionDrifted reports whether the user had been changed in the database by hand on the last resync
+	ConditionDrifted = "Drifted"
+
+	// MongoDBExternalHorizon is the replica set horizon announced to external clients
+	MongoDBExternalHorizon = "external"
+
 	// User
 	MongoDBUSerCreated = "Created"
 	MongoDBUserFailed  = "Failed"
+
+	// Backup
+	BackupModeSnapshot   BackupMode  = "Snapshot"
+	BackupPhasePending   BackupPhase = "Pending"
+	BackupPhaseRunning   BackupPhase = "Running"
+	BackupPhaseSucceeded BackupPhase = "Succeeded"
+	BackupPhaseFailed    BackupPhase = "Failed"
+
+	// VolumeSnapshotGroup is the API group of the snapshots used as data source
+	VolumeSnapshotGroup = "snapshot.storage.k8s.io"
+	// VolumeSnapshotKind is the kind of the snapshots used as data source
+	VolumeSnapshotKind = "VolumeSnapshot"
 )
@@ -17,19 +17,41 @@ Created on 04/04/2021
 package v1alpha1
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/w6d-io/mongodb/internal/config"
 	"github.com/w6d-io/mongodb/internal/util"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+// The storage classes are cluster-scoped, the marker sits out of the controllers so that the namespaced Role is generated without it
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
 var (
 	AccessModes = []string{
 		"ReadWriteOnce",
 		"ReadOnlyMany",
 		"ReadWriteMany",
 	}
+	// ForbiddenExporterArgs are the exporter flags carrying credentials
+	ForbiddenExporterArgs = []string{
+		"--mongodb.uri",
+		"--mongodb.user",
+		"--mongodb.password",
+	}
 )
 
 func DBCreate(mongoDB *MongoDB) error {
@@ -42,6 +64,15 @@ func DBCreate(mongoDB *MongoDB) error {
 					"it should be either ReadWriteOnce, ReadOnlyMny or ReadWriteMany"))
 		}
 	}
+	allErrs = append(allErrs, validateVersion(mongoDB)...)
+	allErrs = append(allErrs, validatePercona(mongoDB)...)
+	allErrs = append(allErrs, validateMonitoring(mongoDB)...)
+	allErrs = append(allErrs, validateExternalAccess(mongoDB)...)
+	allErrs = append(allErrs, validateMembers(mongoDB)...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(mongoDB)...)
+	allErrs = append(allErrs, validateHibernation(mongoDB)...)
+	allErrs = append(allErrs, validateTTL(mongoDB)...)
+	allErrs = append(allErrs, validateDataSource(mongoDB)...)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -68,6 +99,37 @@ func DBUpdate(old, new *MongoDB) error {
 			}
 		}
 	}
+	allErrs = append(allErrs, validateStorageExpansion(webhookClient, field.NewPath("spec").Child("storage"),
+		&old.Spec.Storage, &new.Spec.Storage)...)
+	if old.Spec.Hidden != nil && old.Spec.Hidden.Storage != nil && new.Spec.Hidden != nil && new.Spec.Hidden.Storage != nil {
+		allErrs = append(allErrs, validateStorageExpansion(webhookClient, field.NewPath("spec").Child("hidden").Child("storage"),
+			old.Spec.Hidden.Storage, new.Spec.Hidden.Storage)...)
+	}
+	// the instances created from a version since removed from the catalog are still updated and deleted
+	if old.Spec.Version != new.Spec.Version {
+		allErrs = append(allErrs, validateVersion(new)...)
+	}
+	allErrs = append(allErrs, validatePercona(new)...)
+	if old.Spec.GetFlavor() != new.Spec.GetFlavor() {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("flavor"),
+				"flavor cannot be changed, the images do not share the layout of the data"))
+	}
+	if !reflect.DeepEqual(getEncryption(old), getEncryption(new)) {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("percona").Child("encryption"),
+				"encryption cannot be changed once the data is written"))
+	}
+	allErrs = append(allErrs, validateMonitoring(new)...)
+	allErrs = append(allErrs, validateExternalAccess(new)...)
+	allErrs = append(allErrs, validateMembers(new)...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(new)...)
+	allErrs = append(allErrs, validateHibernation(new)...)
+	allErrs = append(allErrs, validateTTL(new)...)
+	if !reflect.DeepEqual(old.Spec.DataSource, new.Spec.DataSource) {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("dataSource"), "data source cannot be changed"))
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -76,6 +138,377 @@ func DBUpdate(old, new *MongoDB) error {
 		old.Name, allErrs)
 }
 
+func DBDelete(mongoDB *MongoDB) error {
+	var allErrs field.ErrorList
+	if mongoDB.Spec.TerminationPolicy == TerminationPolicyDoNotTerminate {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("terminationPolicy"),
+				mongoDB.Spec.TerminationPolicy,
+				"the termination policy prevents the deletion, it must be changed first"))
+	}
+	if users := getReferencingUsers(webhookClient, mongoDB); len(users) != 0 {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("metadata").Child("name"),
+				"the instance is still referenced by the MongoDBUsers "+strings.Join(users, ", ")+", they must be deleted first"))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: "db.w6d.io", Kind: "MongoDB"},
+		mongoDB.Name, allErrs)
+}
+
+// getReferencingUsers return the names of the MongoDBUsers referencing the instance through their dbref
+func getReferencingUsers(r client.Reader, mongoDB *MongoDB) []string {
+	var users []string
+	if r == nil {
+		return users
+	}
+	list := &MongoDBUserList{}
+	if err := r.List(context.Background(), list, client.InNamespace(mongoDB.Namespace)); err != nil {
+		mongodblog.Error(err, "list MongoDBUsers failed", "name", mongoDB.Name)
+		return users
+	}
+	for _, usr := range list.Items {
+		if usr.Spec.DBRef != nil && usr.Spec.DBRef.Name == mongoDB.Name && usr.DeletionTimestamp == nil {
+			users = append(users, usr.Name)
+		}
+	}
+	return users
+}
+
+// validateStorageExpansion rejects shrinking the storage and growing it when the storage class
+// does not allow volume expansion
+func validateStorageExpansion(r client.Reader, path *field.Path, old, new *corev1.PersistentVolumeClaimSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	path = path.Child("resources").Child("requests").Child("storage")
+	oldSize, ok := old.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return allErrs
+	}
+	newSize := new.Resources.Requests[corev1.ResourceStorage]
+	switch newSize.Cmp(oldSize) {
+	case 0:
+		return allErrs
+	case -1:
+		return append(allErrs, field.Invalid(path, newSize.String(), "storage cannot be shrunk"))
+	}
+	if r == nil {
+		return append(allErrs, field.InternalError(path, errors.New("storage class cannot be checked")))
+	}
+	sc, err := getStorageClass(r, new.StorageClassName)
+	if err != nil {
+		return append(allErrs, field.InternalError(path, err))
+	}
+	if sc == nil || sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		allErrs = append(allErrs,
+			field.Invalid(path, newSize.String(), "the storage class does not allow volume expansion"))
+	}
+	return allErrs
+}
+
+// getStorageClass return the storage class from its name or the default storage class when name is empty
+func getStorageClass(r client.Reader, name *string) (*storagev1.StorageClass, error) {
+	ctx := context.Background()
+	if name != nil && *name != "" {
+		sc := &storagev1.StorageClass{}
+		if err := r.Get(ctx, types.NamespacedName{Name: *name}, sc); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return sc, nil
+	}
+	scs := &storagev1.StorageClassList{}
+	if err := r.List(ctx, scs); err != nil {
+		return nil, err
+	}
+	for i := range scs.Items {
+		if scs.Items[i].Annotations[DefaultStorageClassAnnotation] == "true" {
+			return &scs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// validateDataSource checks the data source is a volume snapshot and the credentials of the source are provided
+func validateDataSource(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	ds := mongoDB.Spec.DataSource
+	if ds == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("dataSource")
+	if ds.APIGroup == nil || *ds.APIGroup != VolumeSnapshotGroup {
+		allErrs = append(allErrs, field.NotSupported(path.Child("apiGroup"), ds.APIGroup, []string{VolumeSnapshotGroup}))
+	}
+	if ds.Kind != VolumeSnapshotKind {
+		allErrs = append(allErrs, field.NotSupported(path.Child("kind"), ds.Kind, []string{VolumeSnapshotKind}))
+	}
+	if ds.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), "snapshot name is required"))
+	}
+	if mongoDB.Spec.AuthSecret == nil || mongoDB.Spec.AuthSecret.Name == "" {
+		allErrs = append(allErrs,
+			field.Required(field.NewPath("spec").Child("authSecret"),
+				"the credentials of the source instance are required to restore its data"))
+	}
+	return allErrs
+}
+
+// validateVersion rejects the versions missing from the catalog of the operator configuration
+func validateVersion(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	if !config.HasVersions() {
+		return allErrs
+	}
+	if _, ok := config.GetVersion(mongoDB.Spec.Version); !ok {
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec").Child("version"), mongoDB.Spec.Version, config.GetVersions()))
+	}
+	return allErrs
+}
+
+// validatePercona rejects the Percona options with another flavor
+func validatePercona(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	percona := mongoDB.Spec.Percona
+	if percona == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("percona")
+	if mongoDB.Spec.GetFlavor() != FlavorPercona {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the Percona options are only allowed with the Percona flavor"))
+	}
+	if percona.Encryption != nil {
+		if percona.Encryption.KeySecret.Name == "" {
+			allErrs = append(allErrs,
+				field.Required(path.Child("encryption").Child("keySecret").Child("name"), "key secret name is required"))
+		}
+		if percona.Encryption.KeySecret.Key == "" {
+			allErrs = append(allErrs,
+				field.Required(path.Child("encryption").Child("keySecret").Child("key"), "key secret key is required"))
+		}
+	}
+	if percona.AuditLog != nil && percona.AuditLog.Filter != "" {
+		var filter map[string]interface{}
+		if err := json.Unmarshal([]byte(percona.AuditLog.Filter), &filter); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("auditLog").Child("filter"), percona.AuditLog.Filter,
+					"it should be a JSON document: "+err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// getEncryption return the encryption of the data of the instance
+func getEncryption(mongoDB *MongoDB) *EncryptionSpec {
+	if mongoDB.Spec.Percona == nil {
+		return nil
+	}
+	return mongoDB.Spec.Percona.Encryption
+}
+
+func validateMonitoring(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	if mongoDB.Spec.Monitoring == nil {
+		return allErrs
+	}
+	for i, arg := range mongoDB.Spec.Monitoring.ExtraArgs {
+		for _, forbidden := range ForbiddenExporterArgs {
+			if arg == forbidden || strings.HasPrefix(arg, forbidden+"=") {
+				allErrs = append(allErrs,
+					field.Invalid(field.NewPath("spec").Child("monitoring").Child("extraArgs").Index(i),
+						arg,
+						"credentials cannot be passed as argument"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateExternalAccess(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	ea := mongoDB.Spec.ExternalAccess
+	if ea == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("externalAccess")
+	if mongoDB.Spec.TLS == nil {
+		allErrs = append(allErrs,
+			field.Invalid(path, nil,
+				"replica set horizons require tls to be set"))
+	}
+	if ea.Type != corev1.ServiceTypeLoadBalancer && ea.Type != corev1.ServiceTypeNodePort {
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("type"), ea.Type,
+				[]string{string(corev1.ServiceTypeLoadBalancer), string(corev1.ServiceTypeNodePort)}))
+	}
+	if len(ea.LoadBalancerIPs) != 0 && ea.Type != corev1.ServiceTypeLoadBalancer {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("loadBalancerIPs"), ea.LoadBalancerIPs,
+				"only supported with LoadBalancer type"))
+	}
+	if len(ea.NodePorts) != 0 && ea.Type != corev1.ServiceTypeNodePort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("nodePorts"), ea.NodePorts,
+				"only supported with NodePort type"))
+	}
+	for i, ip := range ea.LoadBalancerIPs {
+		if net.ParseIP(ip) == nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("loadBalancerIPs").Index(i), ip,
+					"must be a valid IP address"))
+		}
+	}
+	return allErrs
+}
+
+func validateMembers(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec")
+	var voting, replicas int32
+	if mongoDB.Spec.Replicas != nil {
+		replicas = *mongoDB.Spec.Replicas
+	}
+	voting += replicas
+	electable := replicas
+	indexes := make(map[int32]bool)
+	for i, member := range mongoDB.Spec.Members {
+		memberPath := path.Child("members").Index(i)
+		if member.Index < 0 || member.Index >= replicas {
+			allErrs = append(allErrs,
+				field.Invalid(memberPath.Child("index"), member.Index,
+					"must be lower than the number of replicas"))
+		}
+		if indexes[member.Index] {
+			allErrs = append(allErrs,
+				field.Duplicate(memberPath.Child("index"), member.Index))
+		}
+		indexes[member.Index] = true
+		priority := int32(1)
+		if member.Priority != nil {
+			priority = *member.Priority
+		}
+		if priority < 0 || priority > 1000 {
+			allErrs = append(allErrs,
+				field.Invalid(memberPath.Child("priority"), priority,
+					"must be between 0 and 1000"))
+		}
+		if member.Votes != nil && *member.Votes != 0 && *member.Votes != 1 {
+			allErrs = append(allErrs,
+				field.Invalid(memberPath.Child("votes"), *member.Votes,
+					"must be either 0 or 1"))
+		}
+		if member.Votes != nil && *member.Votes == 0 {
+			voting--
+			if priority != 0 {
+				allErrs = append(allErrs,
+					field.Invalid(memberPath.Child("priority"), priority,
+						"a non-voting member must have a priority of 0"))
+			}
+		}
+		if member.SecondaryDelaySecs != nil && *member.SecondaryDelaySecs < 0 {
+			allErrs = append(allErrs,
+				field.Invalid(memberPath.Child("secondaryDelaySecs"), *member.SecondaryDelaySecs,
+					"must be greater than or equal to 0"))
+		}
+		if member.SecondaryDelaySecs != nil && *member.SecondaryDelaySecs > 0 && priority != 0 {
+			allErrs = append(allErrs,
+				field.Invalid(memberPath.Child("priority"), priority,
+					"a delayed member must have a priority of 0"))
+		}
+		if priority == 0 {
+			electable--
+		}
+	}
+	if replicas > 0 && electable <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("members"), nil,
+				"at least one member must be electable as primary"))
+	}
+	if a := mongoDB.Spec.Arbiter; a != nil && a.Replicas != nil {
+		if *a.Replicas < 0 || *a.Replicas > 1 {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("arbiter").Child("replicas"), *a.Replicas,
+					"only one arbiter is supported"))
+		}
+		voting += *a.Replicas
+	}
+	if h := mongoDB.Spec.Hidden; h != nil {
+		if h.Replicas != nil {
+			if *h.Replicas < 0 {
+				allErrs = append(allErrs,
+					field.Invalid(path.Child("hidden").Child("replicas"), *h.Replicas,
+						"must be greater than or equal to 0"))
+			}
+			voting += *h.Replicas
+		}
+		if h.Storage != nil {
+			for _, accessMode := range h.Storage.AccessModes {
+				if !util.StringInArray(string(accessMode), AccessModes) {
+					allErrs = append(allErrs,
+						field.Invalid(path.Child("hidden").Child("storage").Child("accessModes"),
+							accessMode,
+							"it should be either ReadWriteOnce, ReadOnlyMny or ReadWriteMany"))
+				}
+			}
+		}
+	}
+	if voting > MongoDBMaxVotingMembers {
+		allErrs = append(allErrs,
+			field.Invalid(path, voting,
+				"a replica set cannot have more than 7 voting members"))
+	}
+	return allErrs
+}
+
+func validatePodDisruptionBudget(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	pdb := mongoDB.Spec.PodDisruptionBudget
+	if pdb == nil {
+		return allErrs
+	}
+	if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("podDisruptionBudget"), nil,
+				"minAvailable and maxUnavailable cannot be set in the same time"))
+	}
+	return allErrs
+}
+
+// validateHibernation checks the cron expressions and the time zone of the hibernation schedules
+func validateHibernation(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	hibernation := mongoDB.Spec.Hibernation
+	if hibernation == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("hibernation")
+	if _, err := time.LoadLocation(hibernation.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("timeZone"), hibernation.TimeZone, err.Error()))
+	}
+	for i, schedule := range hibernation.Schedules {
+		if _, err := util.ParseCron(schedule.Sleep); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedules").Index(i).Child("sleep"), schedule.Sleep, err.Error()))
+		}
+		if _, err := util.ParseCron(schedule.Wake); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedules").Index(i).Child("wake"), schedule.Wake, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateTTL checks the time to live is positive
+func validateTTL(mongoDB *MongoDB) field.ErrorList {
+	var allErrs field.ErrorList
+	if mongoDB.Spec.TTL != nil && mongoDB.Spec.TTL.Duration <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("ttl"), mongoDB.Spec.TTL.Duration.String(), "ttl must be positive"))
+	}
+	return allErrs
+}
+
 func DBUserCreate(usr *MongoDBUser) error {
 	var allErrs field.ErrorList
 	if usr.Spec.DBRef == nil && usr.Spec.ExternalRef == nil {
@@ -141,6 +574,8 @@ func DBUserCreate(usr *MongoDBUser) error {
 				"privilege must be set",
 			))
 	}
+	allErrs = append(allErrs, validateAdoptionPolicy(usr)...)
+	allErrs = append(allErrs, validateAuthenticationRestrictions(usr)...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -231,6 +666,10 @@ func DBUserUpdate(old, usr *MongoDBUser) error {
 				"privilege must be set",
 			))
 	}
+	if old.Spec.GetAdoptionPolicy() != usr.Spec.GetAdoptionPolicy() {
+		allErrs = append(allErrs, validateAdoptionPolicy(usr)...)
+	}
+	allErrs = append(allErrs, validateAuthenticationRestrictions(usr)...)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -238,3 +677,48 @@ func DBUserUpdate(old, usr *MongoDBUser) error {
 		schema.GroupKind{Group: "db.w6d.io", Kind: "MongoDBUser"},
 		usr.Name, allErrs)
 }
+
+// validateAdoptionPolicy rejects the adoption policies not allowed by the operator configuration
+func validateAdoptionPolicy(usr *MongoDBUser) field.ErrorList {
+	var allErrs field.ErrorList
+	policy := usr.Spec.GetAdoptionPolicy()
+	if !config.IsAdoptionPolicyAllowed(string(policy)) {
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec").Child("adoptionPolicy"), policy, config.GetAdoptionPolicies()))
+	}
+	return allErrs
+}
+
+// validateAuthenticationRestrictions rejects the addresses that are neither an IP address nor a CIDR range
+func validateAuthenticationRestrictions(usr *MongoDBUser) field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("authenticationRestrictions")
+	for i, restriction := range usr.Spec.AuthenticationRestrictions {
+		if len(restriction.ClientSource) == 0 && restriction.ClientSourceFrom == "" && len(restriction.ServerAddress) == 0 {
+			allErrs = append(allErrs,
+				field.Required(path.Index(i), "one of clientSource, clientSourceFrom or serverAddress must be set"))
+		}
+		for j, address := range restriction.ClientSource {
+			if !isAddress(address) {
+				allErrs = append(allErrs,
+					field.Invalid(path.Index(i).Child("clientSource").Index(j), address, "must be an IP address or a CIDR range"))
+			}
+		}
+		for j, address := range restriction.ServerAddress {
+			if !isAddress(address) {
+				allErrs = append(allErrs,
+					field.Invalid(path.Index(i).Child("serverAddress").Index(j), address, "must be an IP address or a CIDR range"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// isAddress return whether the value is an IP address or a CIDR range
+func isAddress(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
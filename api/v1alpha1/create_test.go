@@ -0,0 +1,215 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package v1alpha1
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/w6d-io/mongodb/internal/config"
+)
+
+var _ = Describe("Validation", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "webhook")
+		Expect(err).ToNot(HaveOccurred())
+		filename := filepath.Join(dir, "config.yaml")
+		Expect(ioutil.WriteFile(filename, []byte(`versions:
+  '4.4':
+    mongodb: 'bitnami/mongodb:4.4-debian-10'
+`), 0600)).To(Succeed())
+		Expect(config.New(filename)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	Context("version", func() {
+		var mongoDB *MongoDB
+		BeforeEach(func() {
+			mongoDB = &MongoDB{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       MongoDBSpec{Version: "4.2"},
+			}
+		})
+		It("rejects the creation of a version missing from the catalog", func() {
+			Expect(DBCreate(mongoDB)).ToNot(Succeed())
+			mongoDB.Spec.Version = "4.4"
+			Expect(DBCreate(mongoDB)).To(Succeed())
+		})
+		It("updates the instance of a version removed from the catalog", func() {
+			updated := mongoDB.DeepCopy()
+			updated.Finalizers = []string{"db.w6d.io/finalizer"}
+			Expect(DBUpdate(mongoDB, updated)).To(Succeed())
+		})
+		It("rejects the change to a version missing from the catalog", func() {
+			updated := mongoDB.DeepCopy()
+			updated.Spec.Version = "5.0"
+			Expect(DBUpdate(mongoDB, updated)).ToNot(Succeed())
+			updated.Spec.Version = "4.4"
+			Expect(DBUpdate(mongoDB, updated)).To(Succeed())
+		})
+	})
+	Context("storage expansion", func() {
+		var old, new *corev1.PersistentVolumeClaimSpec
+		path := field.NewPath("spec").Child("storage")
+		allowed, denied := true, false
+		BeforeEach(func() {
+			old = &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			}
+			new = old.DeepCopy()
+			new.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+		})
+		storageClass := func(name string, expansion *bool, isDefault bool) *storagev1.StorageClass {
+			sc := &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: name},
+				AllowVolumeExpansion: expansion,
+			}
+			if isDefault {
+				sc.Annotations = map[string]string{DefaultStorageClassAnnotation: "true"}
+			}
+			return sc
+		}
+		It("accepts the storage unchanged without checking the storage class", func() {
+			Expect(validateStorageExpansion(nil, path, old, old.DeepCopy())).To(BeEmpty())
+		})
+		It("rejects the storage shrunk", func() {
+			Expect(validateStorageExpansion(nil, path, new, old)).To(HaveLen(1))
+		})
+		It("rejects the expansion when the storage class cannot be checked", func() {
+			Expect(validateStorageExpansion(nil, path, old, new)).To(HaveLen(1))
+		})
+		It("handles the storage class named in the claim", func() {
+			name := "expandable"
+			new.StorageClassName = &name
+			r := fake.NewClientBuilder().WithObjects(storageClass("expandable", &allowed, false)).Build()
+			Expect(validateStorageExpansion(r, path, old, new)).To(BeEmpty())
+			r = fake.NewClientBuilder().WithObjects(storageClass("expandable", &denied, false)).Build()
+			Expect(validateStorageExpansion(r, path, old, new)).To(HaveLen(1))
+			r = fake.NewClientBuilder().Build()
+			Expect(validateStorageExpansion(r, path, old, new)).To(HaveLen(1))
+		})
+		It("handles the default storage class when the claim does not name one", func() {
+			r := fake.NewClientBuilder().WithObjects(
+				storageClass("standard", &denied, false),
+				storageClass("default", &allowed, true),
+			).Build()
+			Expect(validateStorageExpansion(r, path, old, new)).To(BeEmpty())
+			r = fake.NewClientBuilder().WithObjects(storageClass("default", nil, true)).Build()
+			Expect(validateStorageExpansion(r, path, old, new)).To(HaveLen(1))
+		})
+	})
+	Context("members", func() {
+		var mongoDB *MongoDB
+		var zero, one, two int32
+		BeforeEach(func() {
+			zero, one, two = 0, 1, 2
+			replicas := int32(3)
+			mongoDB = &MongoDB{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       MongoDBSpec{Version: "4.4", Replicas: &replicas},
+			}
+		})
+		It("accepts the settings of the data-bearing members", func() {
+			delay := int32(3600)
+			mongoDB.Spec.Members = []MemberSpec{
+				{Index: 1, Priority: &zero, Votes: &zero},
+				{Index: 2, Priority: &zero, SecondaryDelaySecs: &delay},
+			}
+			Expect(validateMembers(mongoDB)).To(BeEmpty())
+		})
+		It("rejects the index beyond the replicas or set twice", func() {
+			mongoDB.Spec.Members = []MemberSpec{{Index: 3}, {Index: 1}, {Index: 1}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(2))
+		})
+		It("rejects the invalid votes and priorities", func() {
+			priority := int32(1001)
+			mongoDB.Spec.Members = []MemberSpec{{Index: 0, Priority: &priority}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+			mongoDB.Spec.Members = []MemberSpec{{Index: 0, Votes: &two}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+			mongoDB.Spec.Members = []MemberSpec{{Index: 0, Votes: &zero}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+		})
+		It("rejects the delayed member with a priority", func() {
+			delay := int32(3600)
+			mongoDB.Spec.Members = []MemberSpec{{Index: 0, SecondaryDelaySecs: &delay}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+			delay = -1
+			mongoDB.Spec.Members = []MemberSpec{{Index: 0, Priority: &zero, SecondaryDelaySecs: &delay}}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+		})
+		It("rejects the replica set without electable member", func() {
+			mongoDB.Spec.Members = []MemberSpec{
+				{Index: 0, Priority: &zero}, {Index: 1, Priority: &zero}, {Index: 2, Priority: &zero},
+			}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+		})
+		It("handles the arbiter and hidden members", func() {
+			mongoDB.Spec.Arbiter = &ArbiterSpec{Replicas: &two}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+			mongoDB.Spec.Arbiter.Replicas = &one
+			// the hidden members vote, more than 7 voting members
+			hidden := int32(4)
+			mongoDB.Spec.Hidden = &HiddenSpec{Replicas: &hidden}
+			Expect(validateMembers(mongoDB)).To(HaveLen(1))
+			mongoDB.Spec.Hidden.Replicas = &two
+			Expect(validateMembers(mongoDB)).To(BeEmpty())
+		})
+	})
+	Context("authentication restrictions", func() {
+		var usr *MongoDBUser
+		BeforeEach(func() {
+			usr = &MongoDBUser{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       MongoDBUserSpec{Username: "test"},
+			}
+		})
+		It("accepts the IP addresses, the CIDR ranges and the addresses of the cluster", func() {
+			usr.Spec.AuthenticationRestrictions = []AuthenticationRestriction{
+				{ClientSource: []string{"10.0.0.1", "fd00::/8"}, ServerAddress: []string{"10.1.0.0/16"}},
+				{ClientSourceFrom: ClientSourceFromPodCIDR},
+				{ClientSourceFrom: ClientSourceFromNamespacePods},
+			}
+			Expect(validateAuthenticationRestrictions(usr)).To(BeEmpty())
+		})
+		It("rejects the empty restriction", func() {
+			usr.Spec.AuthenticationRestrictions = []AuthenticationRestriction{{}}
+			Expect(validateAuthenticationRestrictions(usr)).To(HaveLen(1))
+		})
+		It("rejects the invalid addresses", func() {
+			usr.Spec.AuthenticationRestrictions = []AuthenticationRestriction{
+				{ClientSource: []string{"10.0.0.256", "host"}, ServerAddress: []string{"10.1.0.0/33"}},
+			}
+			Expect(validateAuthenticationRestrictions(usr)).To(HaveLen(3))
+		})
+	})
+})
@@ -21,14 +21,26 @@ import (
 	k8sdbv1alpha1 "github.com/w6d-io/mongodb/apis/k8sdb/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // MongoDBSpec defines the desired state of MongoDB
 type MongoDBSpec struct {
 	// Version of MongoDB
+	// +kubebuilder:default="4.4"
 	Version string `json:"version,omitempty"`
 
+	// Flavor is the distribution of the MongoDB image, the flavor of the version in the catalog or
+	// Bitnami by default. It cannot be changed once the instance is created
+	// +optional
+	Flavor Flavor `json:"flavor,omitempty"`
+
+	// Percona configures the options of Percona Server for MongoDB, only allowed with the Percona flavor
+	// +optional
+	Percona *PerconaSpec `json:"percona,omitempty"`
+
 	// Replicas number of instance
+	// +kubebuilder:default=1
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
 
@@ -38,6 +50,11 @@ type MongoDBSpec struct {
 	// AuthSecret contains database secret credential
 	AuthSecret *corev1.LocalObjectReference `json:"authSecret,omitempty"`
 
+	// DataSource is the VolumeSnapshot the volumes of the data-bearing members are restored from.
+	// The restored data holds the users of the source instance so AuthSecret must hold its credentials
+	// +optional
+	DataSource *corev1.TypedLocalObjectReference `json:"dataSource,omitempty"`
+
 	// PodTemplate is a configuration for pod
 	// +optional
 	PodTemplate *k8sv1alpha1.PodTemplate `json:"podTemplate,omitempty"`
@@ -53,6 +70,242 @@ type MongoDBSpec struct {
 	// Port of mongo service to create or if empty will be set with
 	// +optional
 	Port *int32 `json:"port,omitempty"`
+
+	// Monitoring configuration of the metrics exporter sidecar
+	// +optional
+	Monitoring *k8sdbv1alpha1.MonitoringConfig `json:"monitoring,omitempty"`
+
+	// ExternalAccess exposes each member outside of the cluster and configures the replica set horizons
+	// +optional
+	ExternalAccess *ExternalAccess `json:"externalAccess,omitempty"`
+
+	// Arbiter adds arbiter members, voting in elections without holding data
+	// +optional
+	Arbiter *ArbiterSpec `json:"arbiter,omitempty"`
+
+	// Hidden adds hidden members with priority 0, replicating the data without serving client reads
+	// +optional
+	Hidden *HiddenSpec `json:"hidden,omitempty"`
+
+	// Members overrides the replica set settings of the data-bearing members
+	// +optional
+	Members []MemberSpec `json:"members,omitempty"`
+
+	// PodDisruptionBudget of the data-bearing members, created by default when there is more than one replica
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// TerminationPolicy controls the resources kept when the instance is deleted, Halt by default
+	// +kubebuilder:default=Halt
+	// +optional
+	TerminationPolicy TerminationPolicy `json:"terminationPolicy,omitempty"`
+
+	// FinalSnapshot takes a snapshot of each volume before the instance is deleted
+	// +optional
+	FinalSnapshot *FinalSnapshotSpec `json:"finalSnapshot,omitempty"`
+
+	// Paused stops the reconciliation of the instance, the resources are left untouched
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Hibernation scales the members to zero during the sleep windows, the volumes are kept
+	// +optional
+	Hibernation *HibernationSpec `json:"hibernation,omitempty"`
+
+	// TTL is the time to live of the instance from its creation. The expired instance and its users
+	// are deleted according to the termination policy
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// PerconaSpec defines the options of Percona Server for MongoDB
+type PerconaSpec struct {
+	// Encryption enables the encryption at rest of the data with a local key file.
+	// It cannot be changed once the instance is created
+	// +optional
+	Encryption *EncryptionSpec `json:"encryption,omitempty"`
+
+	// AuditLog records the operations run on the instance
+	// +optional
+	AuditLog *AuditLogSpec `json:"auditLog,omitempty"`
+}
+
+// EncryptionSpec defines the encryption at rest of the data
+type EncryptionSpec struct {
+	// KeySecret selects the secret key holding the base64 encoded 32 bytes master key
+	KeySecret corev1.SecretKeySelector `json:"keySecret"`
+
+	// CipherMode of the encryption, AES256-CBC by default
+	// +kubebuilder:validation:Enum=AES256-CBC;AES256-GCM
+	// +kubebuilder:default=AES256-CBC
+	// +optional
+	CipherMode string `json:"cipherMode,omitempty"`
+}
+
+// AuditLogSpec defines the audit log of the operations
+type AuditLogSpec struct {
+	// Destination of the audit log, a file in the data volume by default
+	// +kubebuilder:validation:Enum=file;syslog;console
+	// +kubebuilder:default=file
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// Format of the audit log file, JSON by default
+	// +kubebuilder:validation:Enum=JSON;BSON
+	// +kubebuilder:default=JSON
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Filter is the JSON document selecting the recorded operations, all of them when empty
+	// +optional
+	Filter string `json:"filter,omitempty"`
+}
+
+// GetFlavor return the flavor of the image, Bitnami for the instances created without flavor
+func (in *MongoDBSpec) GetFlavor() Flavor {
+	if in.Flavor == "" {
+		return FlavorBitnami
+	}
+	return in.Flavor
+}
+
+// HibernationSpec defines the windows during which the instance is scaled to zero
+type HibernationSpec struct {
+	// Schedules are the sleep and wake windows
+	// +kubebuilder:validation:MinItems=1
+	Schedules []HibernationSchedule `json:"schedules"`
+
+	// TimeZone of the schedules as an IANA name, UTC when empty
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// HibernationSchedule is a sleep window, the instance sleeps from the sleep time until the next wake time
+type HibernationSchedule struct {
+	// Sleep is the cron expression of the time the instance is scaled to zero
+	Sleep string `json:"sleep"`
+
+	// Wake is the cron expression of the time the instance is scaled back
+	Wake string `json:"wake"`
+}
+
+// FinalSnapshotSpec defines the snapshots taken before the instance is deleted
+type FinalSnapshotSpec struct {
+	// VolumeSnapshotClassName is the class of the snapshots, the default class is used when empty
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// PodDisruptionBudgetSpec overrides the budget computed from the replicas
+type PodDisruptionBudgetSpec struct {
+	// Enabled toggles the PodDisruptionBudget. It is enabled when not set
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MinAvailable is the number or percentage of members that must remain available
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of members that can be evicted.
+	// It defaults to 1 when it preserves the majority of the voting members, 0 otherwise
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// MemberSpec defines the replica set settings of a data-bearing member
+type MemberSpec struct {
+	// Index is the ordinal of the member pod
+	// +kubebuilder:validation:Minimum=0
+	Index int32 `json:"index"`
+
+	// Priority of the member in elections, a member with priority 0 never becomes primary
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// Votes of the member in elections, either 0 or 1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	Votes *int32 `json:"votes,omitempty"`
+
+	// SecondaryDelaySecs is the number of seconds the member lags behind the primary.
+	// A delayed member must have a priority of 0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SecondaryDelaySecs *int32 `json:"secondaryDelaySecs,omitempty"`
+
+	// Tags of the member, used by the read preference tag sets
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// ArbiterSpec defines the arbiter members of the replica set
+type ArbiterSpec struct {
+	// Replicas number of arbiter
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:default=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources of the arbiter container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// HiddenSpec defines the hidden members of the replica set
+type HiddenSpec struct {
+	// Replicas number of hidden member
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources of the hidden member container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Storage spec for persistence of the hidden members, the storage of the instance is used when not set
+	// +optional
+	Storage *corev1.PersistentVolumeClaimSpec `json:"storage,omitempty"`
+}
+
+// ExternalAccess defines the per-member services reachable from outside the cluster
+type ExternalAccess struct {
+	// Type of the per-member services
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +kubebuilder:default=LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations added to the per-member services
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerIPs requested for the members, by ordinal
+	// +optional
+	LoadBalancerIPs []string `json:"loadBalancerIPs,omitempty"`
+
+	// NodePorts requested for the members, by ordinal
+	// +optional
+	NodePorts []int32 `json:"nodePorts,omitempty"`
+
+	// Hosts overrides the discovered external address of the members, by ordinal.
+	// The port of the service is used when the host has no port
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// ExternalEndpoint is the address of a member reachable from outside the cluster
+type ExternalEndpoint struct {
+	// Pod is the name of the member pod
+	Pod string `json:"pod"`
+
+	// Address is the host:port of the member, empty while it is being provisioned
+	// +optional
+	Address string `json:"address,omitempty"`
 }
 
 // MongoDBStatus defines the observed state of MongoDB
@@ -63,6 +316,46 @@ type MongoDBStatus struct {
 
 	// Conditions of the instances
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ExternalEndpoints are the addresses of the members reachable from outside the cluster
+	// +optional
+	ExternalEndpoints []ExternalEndpoint `json:"externalEndpoints,omitempty"`
+
+	// ExpiresAt is the time the instance is deleted, set from the TTL
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Primary is the host of the primary member
+	// +optional
+	Primary string `json:"primary,omitempty"`
+
+	// Members is the state of the replica set members
+	// +optional
+	Members []MemberStatus `json:"members,omitempty"`
+}
+
+// MemberStatus is the state of a member reported by the replica set
+type MemberStatus struct {
+	// Name is the host of the member
+	Name string `json:"name"`
+
+	// State of the member, PRIMARY, SECONDARY, ARBITER...
+	State string `json:"state"`
+
+	// Health is false when the member is unreachable
+	Health bool `json:"health"`
+
+	// Optime is the time of the last operation applied by the member
+	// +optional
+	Optime *metav1.Time `json:"optime,omitempty"`
+
+	// LagSeconds is the replication lag of the secondary behind the primary
+	// +optional
+	LagSeconds *int64 `json:"lagSeconds,omitempty"`
+
+	// SyncSource is the host the member replicates from
+	// +optional
+	SyncSource string `json:"syncSource,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -70,6 +363,8 @@ type MongoDBStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Primary",priority=1,type="string",JSONPath=".status.primary"
+// +kubebuilder:printcolumn:name="Expires",type="string",JSONPath=".status.expiresAt"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MongoDB is the Schema for the mongodbs API
@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"github.com/w6d-io/mongodb/internal/config"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -27,7 +30,11 @@ import (
 // log is for logging in this package.
 var mongodblog = logf.Log.WithName("mongodb-resource")
 
+// webhookClient reads the cluster resources needed by the validation
+var webhookClient client.Reader
+
 func (in *MongoDB) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(in).
 		Complete()
@@ -49,6 +56,26 @@ func (in *MongoDB) Default() {
 	if in.Spec.Version == "" {
 		in.Spec.Version = "4.4"
 	}
+	if in.Spec.Flavor == "" {
+		in.Spec.Flavor = FlavorBitnami
+		if version, ok := config.GetVersion(in.Spec.Version); ok && version.Flavor != "" {
+			in.Spec.Flavor = Flavor(version.Flavor)
+		}
+	}
+	if in.Spec.ExternalAccess != nil && in.Spec.ExternalAccess.Type == "" {
+		in.Spec.ExternalAccess.Type = corev1.ServiceTypeLoadBalancer
+	}
+	if in.Spec.TerminationPolicy == "" {
+		in.Spec.TerminationPolicy = TerminationPolicyHalt
+	}
+	if in.Spec.Arbiter != nil && in.Spec.Arbiter.Replicas == nil {
+		in.Spec.Arbiter.Replicas = new(int32)
+		*in.Spec.Arbiter.Replicas = 1
+	}
+	if in.Spec.Hidden != nil && in.Spec.Hidden.Replicas == nil {
+		in.Spec.Hidden.Replicas = new(int32)
+		*in.Spec.Hidden.Replicas = 1
+	}
 }
 
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-db-w6d-io-v1alpha1-mongodb,mutating=false,failurePolicy=fail,admissionReviewVersions=v1;v1beta1,sideEffects=None,groups=db.w6d.io,resources=mongodbs,versions=v1alpha1,name=validate.mongodb.db.w6d.io
@@ -72,5 +99,5 @@ func (in *MongoDB) ValidateUpdate(old runtime.Object) error {
 func (in *MongoDB) ValidateDelete() error {
 	mongodblog.Info("validate delete", "name", in.Name)
 
-	return nil
+	return DBDelete(in)
 }
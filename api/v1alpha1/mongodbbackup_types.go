@@ -0,0 +1,130 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MongoDBBackupSpec defines the desired state of MongoDBBackup
+type MongoDBBackupSpec struct {
+	// DBRef represents the reference to the mongoDB instance to back up
+	DBRef *corev1.LocalObjectReference `json:"dbref"`
+
+	// Mode of the backup
+	// +kubebuilder:default=Snapshot
+	// +optional
+	Mode BackupMode `json:"mode,omitempty"`
+
+	// VolumeSnapshotClassName is the class of the volume snapshot. The default class is used when empty
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+
+	// FsyncLock flushes and locks the writes of the member while its volume is snapshotted.
+	// When disabled, the snapshot relies on the journal for consistency. It is enabled when not set
+	// +optional
+	FsyncLock *bool `json:"fsyncLock,omitempty"`
+}
+
+// BackupMode defines how the backup is taken
+// +kubebuilder:validation:Enum=Snapshot
+type BackupMode string
+
+// BackupPhase is the state of the backup
+type BackupPhase string
+
+// MongoDBBackupStatus defines the observed state of MongoDBBackup
+type MongoDBBackupStatus struct {
+	// Phase of the backup
+	// +optional
+	Phase BackupPhase `json:"phase,omitempty"`
+
+	// Member is the pod whose volume is snapshotted
+	// +optional
+	Member string `json:"member,omitempty"`
+
+	// Snapshot is the name of the VolumeSnapshot holding the backup
+	// +optional
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// SecretName is the secret holding the credentials of the instance at the time of the backup.
+	// It is meant to be the authSecret of the instances restored from the snapshot
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// StartTime is the time the backup started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the snapshot became ready to use
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message describes the failure of the backup
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// FsyncLock records the lock of the writes of the member until the snapshot is cut
+	// +optional
+	FsyncLock *FsyncLockStatus `json:"fsyncLock,omitempty"`
+}
+
+// FsyncLockStatus defines the lock of the writes of the member taken for the snapshot
+type FsyncLockStatus struct {
+	// Host of the locked member
+	Host string `json:"host"`
+
+	// Time the writes were locked
+	Time metav1.Time `json:"time"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=mongodbbackups,singular=mongodbbackup,shortName=mgb
+//+kubebuilder:printcolumn:name="Instance",type="string",JSONPath=".spec.dbref.name"
+//+kubebuilder:printcolumn:name="Member",priority=1,type="string",JSONPath=".status.member"
+//+kubebuilder:printcolumn:name="Snapshot",type="string",JSONPath=".status.snapshot"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MongoDBBackup is the Schema for the mongodbbackups API
+type MongoDBBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MongoDBBackupSpec   `json:"spec,omitempty"`
+	Status MongoDBBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MongoDBBackupList contains a list of MongoDBBackup
+type MongoDBBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MongoDBBackup `json:"items"`
+}
+
+// IsFsyncLock return whether the writes are locked during the snapshot
+func (in *MongoDBBackupSpec) IsFsyncLock() bool {
+	return in.FsyncLock == nil || *in.FsyncLock
+}
+
+func init() {
+	SchemeBuilder.Register(&MongoDBBackup{}, &MongoDBBackupList{})
+}
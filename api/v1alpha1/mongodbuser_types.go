@@ -39,6 +39,45 @@ type MongoDBUserSpec struct {
 	// ExternalRef refers to the mongo instance do not managed by the operator
 	// +optional
 	ExternalRef *ExternalRef `json:"externalRef,omitempty"`
+
+	// AuthenticationRestrictions restrict the addresses the user authenticates from and to,
+	// the user authenticates when any of them matches
+	// +optional
+	AuthenticationRestrictions []AuthenticationRestriction `json:"authenticationRestrictions,omitempty"`
+
+	// AdoptionPolicy defines whether a user already existing in the database is taken over,
+	// the policies allowed may be restricted by the operator configuration
+	// +kubebuilder:default=Fail
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+}
+
+// AuthenticationRestriction defines the addresses a user authenticates from and to, both must match
+type AuthenticationRestriction struct {
+	// ClientSource are the IP addresses or CIDR ranges the user connects from
+	// +optional
+	ClientSource []string `json:"clientSource,omitempty"`
+
+	// ClientSourceFrom adds the addresses of the cluster to the client sources, the pod CIDR of the
+	// cluster or the IP addresses of the pods running in the namespace of the user
+	// +optional
+	ClientSourceFrom ClientSourceFrom `json:"clientSourceFrom,omitempty"`
+
+	// ServerAddress are the IP addresses or CIDR ranges of the instance the user connects to
+	// +optional
+	ServerAddress []string `json:"serverAddress,omitempty"`
+}
+
+// ClientSourceFrom defines the addresses of the cluster the users connect from
+// +kubebuilder:validation:Enum=PodCIDR;NamespacePods
+type ClientSourceFrom string
+
+// GetAdoptionPolicy return the adoption policy, Fail for the users created without policy
+func (in *MongoDBUserSpec) GetAdoptionPolicy() AdoptionPolicy {
+	if in.AdoptionPolicy == "" {
+		return AdoptionPolicyFail
+	}
+	return in.AdoptionPolicy
 }
 
 type ExternalRef struct {
@@ -85,6 +124,36 @@ type MongoDBUserStatus struct {
 	// Status of the account against mongodb instance
 	// +optional
 	Status string `json:"status,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the user was last created or updated from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// PasswordVersion is the resource version of the password secret the user was last updated from
+	// +optional
+	PasswordVersion string `json:"passwordVersion,omitempty"`
+
+	// Conditions of the user
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Adoption records the takeover of the user when it already existed in the database
+	// +optional
+	Adoption *Adoption `json:"adoption,omitempty"`
+}
+
+// Adoption defines the takeover of an existing user
+type Adoption struct {
+	// PreviousOwner is the uid of the resource that handled the user, empty for a user created by hand
+	// +optional
+	PreviousOwner string `json:"previousOwner,omitempty"`
+
+	// PreviousCustomData is the custom data of the user before the adoption, in extended JSON
+	// +optional
+	PreviousCustomData string `json:"previousCustomData,omitempty"`
+
+	// Time is when the user was adopted
+	Time metav1.Time `json:"time"`
 }
 
 //+kubebuilder:object:root=true
@@ -40,6 +40,11 @@ type PodTemplate struct {
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
-	// SecurityContext is the securityContext for the mongodb container
+	// SecurityContext is the pod-level securityContext
+	// +optional
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty" yaml:"securityContext,omitempty"`
+
+	// ContainerSecurityContext is the securityContext applied to the mongodb, metrics and init containers
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty" yaml:"containerSecurityContext,omitempty"`
 }
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+type MonitoringConfig struct {
+	// Enabled toggles the metrics exporter sidecar. The exporter is enabled when not set
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Image of the metrics exporter. The image from the operator configuration is used when empty
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Collectors to enable on the exporter. It requires an exporter image supporting the `--collector.<name>` flags
+	// +optional
+	Collectors []Collector `json:"collectors,omitempty"`
+
+	// ExtraArgs are additional flags passed to the exporter
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// Resources of the exporter container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ServiceMonitor configuration, created when the prometheus-operator CRD is installed
+	// +optional
+	ServiceMonitor *ServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+
+	// PrometheusRule configuration, created when the prometheus-operator CRD is installed
+	// +optional
+	PrometheusRule *PrometheusRuleConfig `json:"prometheusRule,omitempty"`
+
+	// GrafanaDashboard configuration of the dashboard ConfigMap
+	// +optional
+	GrafanaDashboard *GrafanaDashboardConfig `json:"grafanaDashboard,omitempty"`
+}
+
+type ServiceMonitorConfig struct {
+	// Enabled toggles the ServiceMonitor. It is enabled when not set
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Interval at which metrics are scraped
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// ScrapeTimeout of the scrape request
+	// +optional
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// Labels added to the ServiceMonitor, used by the prometheus serviceMonitorSelector
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type PrometheusRuleConfig struct {
+	// Enabled toggles the PrometheusRule. It is enabled when not set
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Labels added to the PrometheusRule, used by the prometheus ruleSelector
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ReplicationLagSeconds is the replication lag above which an alert is fired
+	// +optional
+	ReplicationLagSeconds *int32 `json:"replicationLagSeconds,omitempty"`
+
+	// ConnectionsPercent is the percentage of used connections above which an alert is fired
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ConnectionsPercent *int32 `json:"connectionsPercent,omitempty"`
+
+	// DiskUsagePercent is the percentage of used storage above which an alert is fired
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DiskUsagePercent *int32 `json:"diskUsagePercent,omitempty"`
+}
+
+type GrafanaDashboardConfig struct {
+	// Enabled toggles the dashboard ConfigMap. It is enabled when not set
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Labels added to the ConfigMap, used by the grafana dashboard sidecar
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Collector is a metrics exporter collector
+// +kubebuilder:validation:Enum=diagnosticdata;replicasetstatus;dbstats;topmetrics;currentopmetrics;indexstats;collstats;profile
+type Collector string
+
+// IsEnabled return whether the metrics exporter has to be deployed
+func (in *MonitoringConfig) IsEnabled() bool {
+	if in == nil || in.Enabled == nil {
+		return true
+	}
+	return *in.Enabled
+}
+
+// IsServiceMonitorEnabled return whether the ServiceMonitor has to be created
+func (in *MonitoringConfig) IsServiceMonitorEnabled() bool {
+	if !in.IsEnabled() {
+		return false
+	}
+	return in == nil || in.ServiceMonitor == nil || in.ServiceMonitor.Enabled == nil || *in.ServiceMonitor.Enabled
+}
+
+// IsPrometheusRuleEnabled return whether the PrometheusRule has to be created
+func (in *MonitoringConfig) IsPrometheusRuleEnabled() bool {
+	if !in.IsEnabled() {
+		return false
+	}
+	return in == nil || in.PrometheusRule == nil || in.PrometheusRule.Enabled == nil || *in.PrometheusRule.Enabled
+}
+
+// IsGrafanaDashboardEnabled return whether the dashboard ConfigMap has to be created
+func (in *MonitoringConfig) IsGrafanaDashboardEnabled() bool {
+	if !in.IsEnabled() {
+		return false
+	}
+	return in == nil || in.GrafanaDashboard == nil || in.GrafanaDashboard.Enabled == nil || *in.GrafanaDashboard.Enabled
+}
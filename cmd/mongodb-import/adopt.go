@@ -0,0 +1,90 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+// adopt stamps the users of the database left without owner with the uid of the MongoDBUser of the
+// namespace referencing the instance, the users handled by another resource are never taken over
+func adopt(ctx context.Context, r client.Client, c *mongo.Client, o options) error {
+	var users struct {
+		Users []user `bson:"users"`
+	}
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "usersInfo", Value: 1},
+	})
+	if err := res.Decode(&users); err != nil {
+		return err
+	}
+	owners := make(map[string]user)
+	for _, usr := range users.Users {
+		owners[usr.User] = usr
+	}
+	list := &db.MongoDBUserList{}
+	if err := r.List(ctx, list, client.InNamespace(o.namespace)); err != nil {
+		return err
+	}
+	for _, mdu := range list.Items {
+		if !isInstanceUser(mdu, o) {
+			continue
+		}
+		usr, ok := owners[mdu.Spec.Username]
+		owner := usr.CustomData.ParentID
+		switch {
+		case !ok:
+			warn("user %s of %s does not exist in the database, skipped", mdu.Spec.Username, mdu.Name)
+			continue
+		case owner == string(mdu.UID):
+			continue
+		case owner != "":
+			warn("user %s is already handled by the resource %s, skipped", mdu.Spec.Username, owner)
+			continue
+		}
+		// the other fields of the custom data are kept
+		customData := bson.M{}
+		for key, value := range usr.CustomData.Others {
+			customData[key] = value
+		}
+		customData["parentID"] = string(mdu.UID)
+		res := c.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "updateUser", Value: mdu.Spec.Username},
+			{Key: "customData", Value: customData},
+		})
+		if res.Err() != nil {
+			return res.Err()
+		}
+		fmt.Printf("user %s adopted by %s/%s\n", mdu.Spec.Username, mdu.Namespace, mdu.Name)
+	}
+	return nil
+}
+
+// isInstanceUser return whether the MongoDBUser references the instance of the flags
+func isInstanceUser(mdu db.MongoDBUser, o options) bool {
+	if o.instance != "" {
+		return mdu.Spec.DBRef != nil && mdu.Spec.DBRef.Name == o.instance
+	}
+	return mdu.Spec.ExternalRef != nil && mdu.Spec.ExternalRef.Service == o.service
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/util"
+)
+
+// PasswordKey is the key of the password in the generated secrets
+const PasswordKey = "password"
+
+// user as returned by usersInfo
+type user struct {
+	User       string `bson:"user"`
+	DB         string `bson:"db"`
+	CustomData struct {
+		ParentID string `bson:"parentID,omitempty"`
+		Others   bson.M `bson:",inline"`
+	} `bson:"customData"`
+	Roles                      []role        `bson:"roles"`
+	AuthenticationRestrictions []restriction `bson:"authenticationRestrictions"`
+}
+
+type restriction struct {
+	ClientSource  []string `bson:"clientSource"`
+	ServerAddress []string `bson:"serverAddress"`
+}
+
+type role struct {
+	Role string `bson:"role"`
+	DB   string `bson:"db"`
+}
+
+// permissions are the roles a MongoDBUser may grant
+var permissions = map[string]bool{
+	"read":      true,
+	"readWrite": true,
+	"dbAdmin":   true,
+	"dbOwner":   true,
+	"userAdmin": true,
+	"root":      true,
+}
+
+var invalidName = regexp.MustCompile("[^a-z0-9.-]+")
+
+// export writes the secrets and the MongoDBUser manifests of the users of the instance. The users the
+// operator could not handle as they are are skipped and reported on the standard error
+func export(ctx context.Context, c *mongo.Client, o options, w io.Writer) error {
+	var users struct {
+		Users []user `bson:"users"`
+	}
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "usersInfo", Value: bson.D{{Key: "forAllDBs", Value: true}}},
+	})
+	if err := res.Decode(&users); err != nil {
+		return err
+	}
+	restrictions, err := getAuthenticationRestrictions(ctx, c)
+	if err != nil {
+		return err
+	}
+	roles, err := getCustomRoles(ctx, c)
+	if err != nil {
+		return err
+	}
+	custom := make(map[role]bool)
+	for _, r := range roles {
+		custom[r] = true
+		grantees := strings.Join(getGrantees(users.Users, r), ", ")
+		if grantees == "" {
+			grantees = "none"
+		}
+		warn("custom role %s@%s is not managed by the operator, users granted it and skipped: %s", r.Role, r.DB, grantees)
+		if _, err = fmt.Fprintf(w, "# custom role %s@%s not managed by the operator, users granted it and skipped: %s\n",
+			r.Role, r.DB, grantees); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool)
+	for _, usr := range users.Users {
+		if !isImportable(usr, custom) {
+			continue
+		}
+		if usr.DB == "admin" {
+			usr.AuthenticationRestrictions = restrictions[usr.User]
+		}
+		name := getName(usr.User, names)
+		for _, obj := range []interface{}{getSecret(usr, name, o), getUser(usr, name, o)} {
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(w, "---\n%s", data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isImportable return whether the user can be handled by a MongoDBUser without losing any of its roles,
+// the custom roles included
+func isImportable(usr user, custom map[role]bool) bool {
+	switch {
+	case usr.DB == "admin" && (usr.User == "root" || usr.User == db.MongoDBMetricsUsername):
+		return false
+	case usr.CustomData.ParentID != "":
+		warn("user %s is already handled by the resource %s, skipped", usr.User, usr.CustomData.ParentID)
+		return false
+	case usr.DB != "admin":
+		warn("user %s is defined in the %s database, only the users of admin can be handled, skipped", usr.User, usr.DB)
+		return false
+	case len(usr.Roles) == 0:
+		warn("user %s has no role, skipped", usr.User)
+		return false
+	}
+	for _, r := range usr.Roles {
+		if custom[r] {
+			warn("user %s is granted the custom role %s@%s not managed by the operator, skipped", usr.User, r.Role, r.DB)
+			return false
+		}
+		if !permissions[r.Role] {
+			warn("user %s is granted the role %s@%s not supported by MongoDBUser, skipped", usr.User, r.Role, r.DB)
+			return false
+		}
+	}
+	return true
+}
+
+// getAuthenticationRestrictions return the authentication restrictions of the users of admin by username,
+// they are not returned for every database at once
+func getAuthenticationRestrictions(ctx context.Context, c *mongo.Client) (map[string][]restriction, error) {
+	var users struct {
+		Users []user `bson:"users"`
+	}
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "usersInfo", Value: 1},
+		{Key: "showAuthenticationRestrictions", Value: true},
+	})
+	if err := res.Decode(&users); err != nil {
+		return nil, err
+	}
+	restrictions := make(map[string][]restriction)
+	for _, usr := range users.Users {
+		restrictions[usr.User] = usr.AuthenticationRestrictions
+	}
+	return restrictions, nil
+}
+
+// getCustomRoles return the roles defined in the databases sorted by role@db
+func getCustomRoles(ctx context.Context, c *mongo.Client) ([]role, error) {
+	databases, err := c.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var roles []role
+	for _, database := range databases {
+		var response struct {
+			Roles []role `bson:"roles"`
+		}
+		res := c.Database(database).RunCommand(ctx, bson.D{
+			{Key: "rolesInfo", Value: 1},
+		})
+		if err := res.Decode(&response); err != nil {
+			return nil, err
+		}
+		roles = append(roles, response.Roles...)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Role+"@"+roles[i].DB < roles[j].Role+"@"+roles[j].DB
+	})
+	return roles, nil
+}
+
+// getGrantees return the users granted the role as user@db
+func getGrantees(users []user, r role) []string {
+	var grantees []string
+	for _, usr := range users {
+		for _, granted := range usr.Roles {
+			if granted == r {
+				grantees = append(grantees, usr.User+"@"+usr.DB)
+				break
+			}
+		}
+	}
+	sort.Strings(grantees)
+	return grantees
+}
+
+// getName return a resource name derived from the username, unique among the names already given
+func getName(username string, names map[string]bool) string {
+	base := strings.Trim(invalidName.ReplaceAllString(strings.ToLower(username), "-"), "-.")
+	if base == "" {
+		base = "user"
+	}
+	name := base
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	names[name] = true
+	return name
+}
+
+// getSecret return the secret holding the password of the user, empty unless generated
+func getSecret(usr user, name string, o options) *corev1.Secret {
+	password := ""
+	if o.generatePasswords {
+		password = util.GeneratePassword(30, 3, 3, 2)
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-password",
+			Namespace: o.namespace,
+		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: map[string]string{PasswordKey: password},
+	}
+}
+
+// getUser return the MongoDBUser granting the roles of the user
+func getUser(usr user, name string, o options) *db.MongoDBUser {
+	mdu := &db.MongoDBUser{
+		TypeMeta: metav1.TypeMeta{APIVersion: db.GroupVersion.String(), Kind: "MongoDBUser"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: o.namespace,
+		},
+		Spec: db.MongoDBUserSpec{
+			Username: usr.User,
+			Password: db.Password{
+				ValueFrom: &db.PasswordFrom{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: name + "-password"},
+						Key:                  PasswordKey,
+					},
+				},
+			},
+		},
+	}
+	for _, r := range usr.Roles {
+		mdu.Spec.Privileges = append(mdu.Spec.Privileges, db.Privilege{
+			DatabaseName: r.DB,
+			Permission:   db.Permission(r.Role),
+		})
+	}
+	for _, r := range usr.AuthenticationRestrictions {
+		mdu.Spec.AuthenticationRestrictions = append(mdu.Spec.AuthenticationRestrictions, db.AuthenticationRestriction{
+			ClientSource:  r.ClientSource,
+			ServerAddress: r.ServerAddress,
+		})
+	}
+	if o.instance != "" {
+		mdu.Spec.DBRef = &corev1.LocalObjectReference{Name: o.instance}
+		return mdu
+	}
+	var port *int32
+	if o.port != 0 {
+		p := int32(o.port)
+		port = &p
+	}
+	mdu.Spec.ExternalRef = &db.ExternalRef{
+		Service: o.service,
+		Port:    port,
+		Auth:    &corev1.LocalObjectReference{Name: o.authSecret},
+	}
+	return mdu
+}
+
+func warn(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
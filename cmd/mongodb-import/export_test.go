@@ -0,0 +1,84 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+var _ = Describe("Export", func() {
+	Context("importable users", func() {
+		var usr user
+		custom := map[role]bool{{Role: "reporting", DB: "app"}: true}
+		BeforeEach(func() {
+			usr = user{User: "alice", DB: "admin", Roles: []role{{Role: "readWrite", DB: "app"}}}
+		})
+		It("imports the user of admin granted the supported roles", func() {
+			Expect(isImportable(usr, custom)).To(BeTrue())
+		})
+		It("skips the accounts of the operator", func() {
+			usr.User = "root"
+			Expect(isImportable(usr, custom)).To(BeFalse())
+			usr.User = db.MongoDBMetricsUsername
+			Expect(isImportable(usr, custom)).To(BeFalse())
+		})
+		It("skips the user handled by a resource", func() {
+			usr.CustomData.ParentID = "uid"
+			Expect(isImportable(usr, custom)).To(BeFalse())
+		})
+		It("skips the user defined in another database or without role", func() {
+			usr.DB = "app"
+			Expect(isImportable(usr, custom)).To(BeFalse())
+			usr.DB, usr.Roles = "admin", nil
+			Expect(isImportable(usr, custom)).To(BeFalse())
+		})
+		It("skips the user granted a custom or unsupported role", func() {
+			usr.Roles = append(usr.Roles, role{Role: "reporting", DB: "app"})
+			Expect(isImportable(usr, custom)).To(BeFalse())
+			usr.Roles = []role{{Role: "clusterMonitor", DB: "admin"}}
+			Expect(isImportable(usr, custom)).To(BeFalse())
+		})
+	})
+	Context("grantees", func() {
+		It("lists the users granted the role", func() {
+			users := []user{
+				{User: "bob", DB: "admin", Roles: []role{{Role: "reporting", DB: "app"}}},
+				{User: "alice", DB: "app", Roles: []role{{Role: "read", DB: "app"}, {Role: "reporting", DB: "app"}}},
+				{User: "carol", DB: "admin", Roles: []role{{Role: "reporting", DB: "other"}}},
+			}
+			Expect(getGrantees(users, role{Role: "reporting", DB: "app"})).To(Equal([]string{"alice@app", "bob@admin"}))
+			Expect(getGrantees(users, role{Role: "unused", DB: "app"})).To(BeEmpty())
+		})
+	})
+	Context("names", func() {
+		It("derives a valid resource name from the username", func() {
+			names := make(map[string]bool)
+			Expect(getName("Alice_Smith", names)).To(Equal("alice-smith"))
+			Expect(getName("-app.reader-", names)).To(Equal("app.reader"))
+			Expect(getName("___", names)).To(Equal("user"))
+		})
+		It("gives unique names", func() {
+			names := make(map[string]bool)
+			Expect(getName("alice", names)).To(Equal("alice"))
+			Expect(getName("Alice", names)).To(Equal("alice-2"))
+			Expect(getName("ALICE", names)).To(Equal("alice-3"))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/mongodb"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(db.AddToScheme(scheme))
+}
+
+// options of the import
+type options struct {
+	namespace         string
+	instance          string
+	service           string
+	port              int
+	uri               string
+	authSecret        string
+	output            string
+	generatePasswords bool
+	adopt             bool
+}
+
+// mongodb-import emits the MongoDBUser manifests of the users of an instance managed by hand, the
+// passwords are referenced from generated secrets. Once the manifests applied, the --adopt flag
+// stamps the users of the database with the uid of their resource so that the operator takes them over
+func main() {
+	var o options
+	flag.StringVar(&o.namespace, "namespace", "default", "Namespace of the instance and of the manifests.")
+	flag.StringVar(&o.instance, "instance", "", "Name of the MongoDB resource of the instance.")
+	flag.StringVar(&o.service, "service", "",
+		"Address of the instance, the service of the instance when empty. Required for an instance not managed by the operator.")
+	flag.IntVar(&o.port, "port", 0, "Port of the instance, 27017 when empty.")
+	flag.StringVar(&o.uri, "uri", "",
+		"Connection string of the instance reached from outside of the cluster, e.g. "+
+			"mongodb://localhost:27017/?directConnection=true through kubectl port-forward to the primary. "+
+			"The root credentials of the auth secret are used, --service and --port still fill in the manifests.")
+	flag.StringVar(&o.authSecret, "auth-secret", "",
+		"Secret holding the mongodb-root-password of the instance. Required for an instance not managed by the operator.")
+	flag.StringVar(&o.output, "output", "-", "File the manifests are written to, - for the standard output.")
+	flag.BoolVar(&o.generatePasswords, "generate-passwords", false,
+		"Fill the generated secrets with random passwords, applying them changes the passwords of the users. "+
+			"The secrets are left empty to be filled in with the current passwords otherwise.")
+	flag.BoolVar(&o.adopt, "adopt", false,
+		"Stamp the users of the database with the uid of the MongoDBUser resources applied from the manifests.")
+	flag.Parse()
+
+	if err := run(context.Background(), o); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, o options) error {
+	if o.instance == "" && (o.service == "" || o.authSecret == "") {
+		return errors.New("either --instance or --service and --auth-secret must be set")
+	}
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	r, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	mongoDB, err := getMongoDB(ctx, r, o)
+	if err != nil {
+		return err
+	}
+	c, err := getClient(ctx, r, mongoDB, o)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	if err = c.Ping(ctx, nil); err != nil {
+		return err
+	}
+	if o.adopt {
+		return adopt(ctx, r, c, o)
+	}
+	var w io.Writer = os.Stdout
+	if o.output != "-" {
+		f, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+	return export(ctx, c, o, w)
+}
+
+// getClient return the client connected through the connection string of the flags or the service of the instance
+func getClient(ctx context.Context, r client.Client, mongoDB *db.MongoDB, o options) (*mongo.Client, error) {
+	if o.uri != "" {
+		return mongodb.GetURIClient(ctx, r, mongoDB, o.uri)
+	}
+	return mongodb.GetClient(ctx, r, mongoDB)
+}
+
+// getMongoDB return the instance the client connects to, the flags take precedence over the resource
+func getMongoDB(ctx context.Context, r client.Client, o options) (*db.MongoDB, error) {
+	mongoDB := &db.MongoDB{}
+	if o.instance != "" {
+		if err := r.Get(ctx, types.NamespacedName{Name: o.instance, Namespace: o.namespace}, mongoDB); err != nil {
+			return nil, err
+		}
+	}
+	mongoDB.Namespace = o.namespace
+	if o.service != "" {
+		mongoDB.Spec.Service = &corev1.LocalObjectReference{Name: o.service}
+	}
+	if o.port != 0 {
+		port := int32(o.port)
+		mongoDB.Spec.Port = &port
+	}
+	if o.authSecret != "" {
+		mongoDB.Spec.AuthSecret = &corev1.LocalObjectReference{Name: o.authSecret}
+	}
+	return mongoDB, nil
+}
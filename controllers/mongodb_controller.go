@@ -19,29 +19,42 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
+	internalmongodb "github.com/w6d-io/mongodb/internal/mongodb"
 	"github.com/w6d-io/mongodb/internal/util"
 	"github.com/w6d-io/mongodb/pkg/controllers/mongodb"
+	"github.com/w6d-io/mongodb/pkg/controllers/mongodb/user"
+	"github.com/w6d-io/mongodb/pkg/k8s/monitoring"
+	"github.com/w6d-io/mongodb/pkg/k8s/pdb"
+	"github.com/w6d-io/mongodb/pkg/k8s/service"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 // MongoDBReconciler reconciles a MongoDB object
 type MongoDBReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbs,verbs=get;list;watch;create;update;patch;delete
@@ -54,6 +67,11 @@ type MongoDBReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	correlationID := uuid.New().String()
@@ -72,6 +90,42 @@ func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	if mdb.GetDeletionTimestamp() != nil {
+		return r.finalize(ctx, mdb)
+	}
+	if !controllerutil.ContainsFinalizer(mdb, FinalizerName) {
+		controllerutil.AddFinalizer(mdb, FinalizerName)
+		if err = r.Update(ctx, mdb); err != nil {
+			log.Error(err, "add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+	if mdb.Spec.Paused {
+		log.Info("reconciliation paused")
+		// the disruptions of a paused instance are not guarded, the budget is removed
+		if err = pdb.CreateUpdate(ctx, r.Client, r.Scheme, mdb); err != nil {
+			log.Error(err, "pod disruption budget processing failed")
+			return ctrl.Result{}, err
+		}
+		if mdb.Status.Phase != db.MongoDBPhasePaused {
+			mdb.Status.Phase = db.MongoDBPhasePaused
+			if err = r.Status().Update(ctx, mdb); err != nil {
+				log.Error(err, "unable to update MongoDB status")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	deleted, expiry, err := r.expire(ctx, mdb)
+	if err != nil {
+		log.Error(err, "expiry processing failed")
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		return ctrl.Result{RequeueAfter: expiry}, nil
+	}
+	wake := r.hibernate(ctx, mdb)
+
 	sts := &appsv1.StatefulSet{}
 	err = r.Get(ctx, req.NamespacedName, sts)
 	if err != nil && errors.IsNotFound(err) {
@@ -86,16 +140,277 @@ func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		log.Error(err, "failed to get StatefulSet")
 		return ctrl.Result{}, err
 	}
+	if err = mongodb.CreateUpdate(ctx, r.Client, r.Scheme, mdb); err != nil {
+		log.Error(err, "failed to update resources")
+		return ctrl.Result{}, err
+	}
 	log.V(1).Info("update sts")
 	if err = r.updateSTS(ctx, req, mdb); err != nil {
 		log.Error(err, "update sts failed")
 		return ctrl.Result{Requeue: true}, client.IgnoreNotFound(err)
 	}
+	log.V(1).Info("expand volumes")
+	expanding := r.expandVolumes(ctx, mdb)
+	if mdb.Spec.ExternalAccess == nil && len(mdb.Status.ExternalEndpoints) != 0 && mdb.Status.Phase == db.MongoDBPhaseReady {
+		log.V(1).Info("remove replica set horizons")
+		if err = internalmongodb.SetHorizons(ctx, r.Client, mdb, nil); err != nil {
+			log.Error(err, "remove horizons failed")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
+	// the members restored from a snapshot have no primary until their configuration is replaced
+	if err = r.restore(ctx, mdb); err != nil {
+		log.Error(err, "restored replica set processing failed")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
 	log.V(1).Info("update status")
-	if err = r.UpdateStatus(ctx, mdb, sts); err != nil {
+	if err = r.UpdateStatus(ctx, mdb); err != nil {
 		log.Error(err, "update status failed")
 		return ctrl.Result{Requeue: true}, err
 	}
+	if expanding {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if mdb.Status.Phase == db.MongoDBPhaseReady {
+		if err = internalmongodb.CreateUpdateMonitoringUser(ctx, r.Client, mdb); err != nil {
+			log.Error(err, "monitoring user processing failed")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if err = internalmongodb.SetRoles(ctx, r.Client, mdb); err != nil {
+			log.Error(err, "role labels processing failed")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+	// the members join and leave the replica set as soon as one of them is ready, the instance is not Ready
+	// until the members not joined by the image are added and the members no longer requested are removed
+	joinable, err := internalmongodb.HasReadyMember(ctx, r.Client, mdb)
+	if err != nil {
+		log.Error(err, "get ready members failed")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if joinable {
+		pending, err := internalmongodb.SetMembers(ctx, r.Client, mdb)
+		if err != nil {
+			log.Error(err, "replica set members processing failed")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if pending {
+			log.V(1).Info("waiting for replica set members")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+	if mdb.Spec.ExternalAccess != nil {
+		if result, err := r.setHorizons(ctx, mdb); err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+	result := ctrl.Result{}
+	if mdb.Status.Phase == db.MongoDBPhaseReady {
+		// poll the replica set to follow the primary after a failover
+		result.RequeueAfter = RoleResyncPeriod
+	}
+	for _, after := range []time.Duration{wake, expiry} {
+		if after > 0 && (result.RequeueAfter == 0 || after < result.RequeueAfter) {
+			result.RequeueAfter = after
+		}
+	}
+	return result, nil
+}
+
+// finalize enforces the termination policy then releases the instance
+func (r *MongoDBReconciler) finalize(ctx context.Context, mdb *db.MongoDB) (ctrl.Result, error) {
+	log := util.GetLog(ctx, mdb).WithName("Finalize")
+	if !controllerutil.ContainsFinalizer(mdb, FinalizerName) {
+		return ctrl.Result{}, nil
+	}
+	done, err := mongodb.Terminate(ctx, r.Client, mdb)
+	if err != nil {
+		log.Error(err, "termination failed")
+		return ctrl.Result{}, err
+	}
+	if !done {
+		if mdb.Spec.TerminationPolicy == db.TerminationPolicyDoNotTerminate {
+			// wait for the policy to be changed
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	controllerutil.RemoveFinalizer(mdb, FinalizerName)
+	if err = r.Update(ctx, mdb); err != nil {
+		log.Error(err, "remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// expandVolumes grows the volumes to the requested storage and reports the progress in the conditions.
+// It returns whether the expansion is still in progress
+func (r *MongoDBReconciler) expandVolumes(ctx context.Context, mdb *db.MongoDB) bool {
+	log := util.GetLog(ctx, mdb).WithName("ExpandVolumes")
+	condition := metav1.Condition{
+		Type:               db.ConditionVolumeExpansion,
+		ObservedGeneration: mdb.Generation,
+	}
+	message, err := statefulset.ExpandVolumes(ctx, r.Client, mdb)
+	switch {
+	case err != nil:
+		log.Error(err, "volume expansion failed")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Failed"
+		condition.Message = err.Error()
+	case message != "":
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Expanding"
+		condition.Message = message
+	case meta.IsStatusConditionTrue(mdb.Status.Conditions, db.ConditionVolumeExpansion):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Completed"
+		condition.Message = "volumes expanded"
+	default:
+		return false
+	}
+	meta.SetStatusCondition(&mdb.Status.Conditions, condition)
+	return err != nil || message != ""
+}
+
+// expire deletes the instance and its users once the time to live is elapsed, a warning is emitted
+// shortly before. It returns whether the instance is being deleted and the time until the next step
+func (r *MongoDBReconciler) expire(ctx context.Context, mdb *db.MongoDB) (bool, time.Duration, error) {
+	log := util.GetLog(ctx, mdb).WithName("Expire")
+	if mdb.Spec.TTL == nil {
+		mdb.Status.ExpiresAt = nil
+		meta.RemoveStatusCondition(&mdb.Status.Conditions, db.ConditionExpiring)
+		return false, 0, nil
+	}
+	expiresAt := metav1.NewTime(mdb.CreationTimestamp.Add(mdb.Spec.TTL.Duration))
+	mdb.Status.ExpiresAt = &expiresAt
+	remaining := time.Until(expiresAt.Time)
+	if remaining > ExpiryWarningPeriod {
+		meta.RemoveStatusCondition(&mdb.Status.Conditions, db.ConditionExpiring)
+		return false, remaining - ExpiryWarningPeriod, nil
+	}
+	condition := metav1.Condition{
+		Type:               db.ConditionExpiring,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Expiring",
+		Message:            fmt.Sprintf("instance expires at %s", expiresAt.UTC().Format(time.RFC3339)),
+		ObservedGeneration: mdb.Generation,
+	}
+	policy := mdb.Spec.TerminationPolicy
+	if remaining <= 0 && policy == db.TerminationPolicyDoNotTerminate {
+		condition.Reason = "Prevented"
+		condition.Message = "instance expired, the deletion is prevented by the termination policy"
+	}
+	current := meta.FindStatusCondition(mdb.Status.Conditions, db.ConditionExpiring)
+	if current == nil || current.Reason != condition.Reason {
+		r.Recorder.Event(mdb, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+	meta.SetStatusCondition(&mdb.Status.Conditions, condition)
+	if remaining > 0 || condition.Reason == "Prevented" {
+		return false, remaining, nil
+	}
+
+	log.Info("instance expired", "expiresAt", expiresAt)
+	// the users are dropped first as they prevent the deletion of the instance
+	users, err := user.ListByMongoDB(ctx, r.Client, mdb)
+	if err != nil {
+		log.Error(err, "list users failed")
+		return false, 0, err
+	}
+	for i := range users {
+		if users[i].DeletionTimestamp != nil {
+			continue
+		}
+		log.V(1).Info("delete user", "user", users[i].Name)
+		if err = r.Delete(ctx, &users[i]); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "delete user failed", "user", users[i].Name)
+			return false, 0, err
+		}
+	}
+	if len(users) != 0 {
+		log.V(1).Info("waiting for the users to be deleted", "count", len(users))
+		return true, 10 * time.Second, nil
+	}
+	log.V(1).Info("delete expired instance")
+	if err = r.Delete(ctx, mdb); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "delete expired instance failed")
+		return false, 0, err
+	}
+	return true, 0, nil
+}
+
+// restore replaces the replica set configuration restored from the data source once, the condition
+// records that the configuration holds the members of the instance
+func (r *MongoDBReconciler) restore(ctx context.Context, mdb *db.MongoDB) error {
+	if mdb.Spec.DataSource == nil || meta.IsStatusConditionTrue(mdb.Status.Conditions, db.ConditionRestored) {
+		return nil
+	}
+	restored, err := internalmongodb.RestoreReplicaSet(ctx, r.Client, mdb)
+	if err != nil || !restored {
+		return err
+	}
+	meta.SetStatusCondition(&mdb.Status.Conditions, metav1.Condition{
+		Type:               db.ConditionRestored,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Restored",
+		Message:            "replica set configuration restored from the data source",
+		ObservedGeneration: mdb.Generation,
+	})
+	return nil
+}
+
+// hibernate reports in the conditions whether the instance is within a sleep window, the members are
+// scaled to zero while the condition is true. It returns the time until the next window starts or ends
+func (r *MongoDBReconciler) hibernate(ctx context.Context, mdb *db.MongoDB) time.Duration {
+	log := util.GetLog(ctx, mdb).WithName("Hibernate")
+	hibernated, next, err := mongodb.IsHibernated(mdb, time.Now())
+	if err != nil {
+		log.Error(err, "hibernation schedules processing failed")
+		return 0
+	}
+	condition := metav1.Condition{
+		Type:               db.ConditionHibernated,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Awake",
+		Message:            "instance is outside of the sleep windows",
+		ObservedGeneration: mdb.Generation,
+	}
+	switch {
+	case hibernated:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Sleeping"
+		condition.Message = "instance is scaled to zero until the next wake time"
+	case meta.FindStatusCondition(mdb.Status.Conditions, db.ConditionHibernated) == nil && mdb.Spec.Hibernation == nil:
+		return 0
+	}
+	if !meta.IsStatusConditionPresentAndEqual(mdb.Status.Conditions, db.ConditionHibernated, condition.Status) {
+		log.Info("hibernation changed", "hibernated", hibernated)
+	}
+	meta.SetStatusCondition(&mdb.Status.Conditions, condition)
+	if next.IsZero() {
+		return 0
+	}
+	return time.Until(next)
+}
+
+// setHorizons configures the external horizon once every member has an external address
+func (r *MongoDBReconciler) setHorizons(ctx context.Context, mdb *db.MongoDB) (ctrl.Result, error) {
+	log := util.GetLog(ctx, mdb).WithName("SetHorizons")
+	addresses := make(map[string]string)
+	for _, endpoint := range mdb.Status.ExternalEndpoints {
+		if endpoint.Address == "" {
+			log.V(1).Info("waiting for external address", "pod", endpoint.Pod)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		addresses[endpoint.Pod] = endpoint.Address
+	}
+	if mdb.Status.Phase != db.MongoDBPhaseReady {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if err := internalmongodb.SetHorizons(ctx, r.Client, mdb, addresses); err != nil {
+		log.Error(err, "set horizons failed")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -104,6 +419,8 @@ func (r *MongoDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&db.MongoDB{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&corev1.Service{}).
+		Owns(&policyv1beta1.PodDisruptionBudget{}).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 10,
 		}).
@@ -118,14 +435,15 @@ func (r *MongoDBReconciler) updateSTS(ctx context.Context, req ctrl.Request, mon
 	if err != nil {
 		return err
 	}
-	if *sts.Spec.Replicas != *mongoDB.Spec.Replicas {
+	replicas := statefulset.GetReplicas(mongoDB, statefulset.DataMember)
+	if *sts.Spec.Replicas != *replicas {
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			sts := &appsv1.StatefulSet{}
 			err = r.Get(ctx, req.NamespacedName, sts)
 			if err != nil {
 				return client.IgnoreNotFound(err)
 			}
-			sts.Spec.Replicas = mongoDB.Spec.Replicas
+			sts.Spec.Replicas = replicas
 			if err := r.Update(ctx, sts); err != nil {
 				return err
 			}
@@ -139,13 +457,22 @@ func (r *MongoDBReconciler) updateSTS(ctx context.Context, req ctrl.Request, mon
 	return nil
 }
 
-func (r *MongoDBReconciler) UpdateStatus(ctx context.Context, mdb *db.MongoDB, sts *appsv1.StatefulSet) error {
+func (r *MongoDBReconciler) UpdateStatus(ctx context.Context, mdb *db.MongoDB) error {
 	log := util.GetLog(ctx, mdb)
 	var err error
+	phase, err := r.GetMongoDBStatus(ctx, mdb)
+	if err != nil {
+		log.Error(err, "get mongodb status failed")
+		return err
+	}
+	if !r.setReplicaSetStatus(ctx, mdb, phase) && phase == db.MongoDBPhaseReady {
+		phase = db.MongoDBPhaseNotReady
+	}
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		mdb.Status.Phase, err = r.GetMongoDBStatus(ctx, mdb, sts)
+		mdb.Status.Phase = phase
+		mdb.Status.ExternalEndpoints, err = service.GetExternalEndpoints(ctx, r.Client, mdb)
 		if err != nil {
-			log.Error(err, "get mongodb status failed")
+			log.Error(err, "get external endpoints failed")
 			return err
 		}
 		if err := r.Status().Update(ctx, mdb); err != nil {
@@ -160,39 +487,103 @@ func (r *MongoDBReconciler) UpdateStatus(ctx context.Context, mdb *db.MongoDB, s
 	return nil
 }
 
-func (r *MongoDBReconciler) GetMongoDBStatus(ctx context.Context, mdb *db.MongoDB, sts *appsv1.StatefulSet) (db.MongoDBPhase, error) {
-	var indexes []int
-	if mdb.Spec.Replicas == nil || *mdb.Spec.Replicas == 0 {
+// GetMongoDBStatus return the phase of the instance from the state of the containers of the data-bearing members
+func (r *MongoDBReconciler) GetMongoDBStatus(ctx context.Context, mdb *db.MongoDB) (db.MongoDBPhase, error) {
+	log := util.GetLog(ctx, mdb)
+	replicas := int(*statefulset.GetReplicas(mdb, statefulset.DataMember))
+	if replicas == 0 {
 		return db.MongoDBPhasePaused, nil
 	}
-	for p := 0; p < int(sts.Status.Replicas); p++ {
-		i, err := r.GetPodStatus(ctx, mdb, p)
-		if err != nil {
-			return "", err
-		}
-		indexes = append(indexes, i)
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mdb.Namespace),
+		client.MatchingLabels(statefulset.GetLabels(mdb, statefulset.DataMember))); err != nil {
+		log.Error(err, "list pods failed")
+		return "", err
 	}
 	var max int
-	for _, index := range indexes {
-		if index > max {
+	if len(pods.Items) < replicas {
+		max = 2
+	}
+	for i := range pods.Items {
+		if index := GetStatusIndex(pods.Items[i].Status.ContainerStatuses); index > max {
 			max = index
 		}
 	}
 	return status[max], nil
 }
 
-func (r *MongoDBReconciler) GetPodStatus(ctx context.Context, mdb *db.MongoDB, index int) (int, error) {
-	log := util.GetLog(ctx, mdb)
-	var err error
-	po := &corev1.Pod{}
-	name := fmt.Sprintf("%s-%d", mdb.Name, index)
-	nn := types.NamespacedName{Name: name, Namespace: mdb.Namespace}
-	err = r.Get(ctx, nn, po)
+// setReplicaSetStatus publishes the members reported by the replica set and the health conditions.
+// The replica set is only queried when the containers are ready. It returns whether the replica set is healthy
+func (r *MongoDBReconciler) setReplicaSetStatus(ctx context.Context, mdb *db.MongoDB, phase db.MongoDBPhase) bool {
+	log := util.GetLog(ctx, mdb).WithName("SetReplicaSetStatus")
+	healthy := metav1.Condition{
+		Type:               db.ConditionReplicaSetHealthy,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: mdb.Generation,
+	}
+	if phase != db.MongoDBPhaseReady {
+		mdb.Status.Primary = ""
+		mdb.Status.Members = nil
+		healthy.Reason = "PodsNotReady"
+		healthy.Message = fmt.Sprintf("instance is %s", phase)
+		meta.SetStatusCondition(&mdb.Status.Conditions, healthy)
+		meta.RemoveStatusCondition(&mdb.Status.Conditions, db.ConditionReplicationLagging)
+		return false
+	}
+	members, err := internalmongodb.GetReplicaSetStatus(ctx, r.Client, mdb)
 	if err != nil {
-		log.Error(err, "get pod failed")
-		return 0, err
+		log.Error(err, "get replica set status failed")
+		mdb.Status.Primary = ""
+		mdb.Status.Members = nil
+		healthy.Reason = "Unreachable"
+		healthy.Message = err.Error()
+		meta.SetStatusCondition(&mdb.Status.Conditions, healthy)
+		meta.RemoveStatusCondition(&mdb.Status.Conditions, db.ConditionReplicationLagging)
+		return false
+	}
+	mdb.Status.Members = members
+	mdb.Status.Primary = ""
+	var unhealthy, lagging []string
+	threshold := int64(monitoring.GetReplicationLagSeconds(mdb))
+	for _, member := range members {
+		if member.State == "PRIMARY" {
+			mdb.Status.Primary = member.Name
+		}
+		// the members no longer requested are unreachable until SetMembers removes them
+		if !member.Health && !internalmongodb.IsRemovedMember(mdb, member.Name) {
+			unhealthy = append(unhealthy, member.Name)
+		}
+		if member.LagSeconds != nil && *member.LagSeconds > threshold {
+			lagging = append(lagging, member.Name)
+		}
+	}
+	switch {
+	case mdb.Status.Primary == "":
+		healthy.Reason = "NoPrimary"
+		healthy.Message = "replica set has no primary"
+	case len(unhealthy) != 0:
+		healthy.Reason = "MemberUnhealthy"
+		healthy.Message = fmt.Sprintf("unreachable members: %s", strings.Join(unhealthy, ", "))
+	default:
+		healthy.Status = metav1.ConditionTrue
+		healthy.Reason = "Healthy"
+		healthy.Message = "replica set has a primary and all its members are reachable"
+	}
+	meta.SetStatusCondition(&mdb.Status.Conditions, healthy)
+	lag := metav1.Condition{
+		Type:               db.ConditionReplicationLagging,
+		Status:             metav1.ConditionFalse,
+		Reason:             "InSync",
+		Message:            fmt.Sprintf("secondaries are less than %ds behind the primary", threshold),
+		ObservedGeneration: mdb.Generation,
+	}
+	if len(lagging) != 0 {
+		lag.Status = metav1.ConditionTrue
+		lag.Reason = "Lagging"
+		lag.Message = fmt.Sprintf("members more than %ds behind the primary: %s", threshold, strings.Join(lagging, ", "))
 	}
-	return GetStatusIndex(po.Status.ContainerStatuses), nil
+	meta.SetStatusCondition(&mdb.Status.Conditions, lag)
+	return healthy.Status == metav1.ConditionTrue
 }
 
 // GetStatusIndex returns a kubernetes State
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/controllers/mongodb/backup"
+	"github.com/w6d-io/mongodb/pkg/k8s/snapshot"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// MongoDBBackupReconciler reconciles a MongoDBBackup object
+type MongoDBBackupReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbbackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbbackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbbackups/finalizers,verbs=update
+
+func (r *MongoDBBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	correlationID := uuid.New().String()
+	ctx = context.WithValue(context.Background(), "correlation_id", correlationID)
+	logger := r.Log.WithValues("backup", req.NamespacedName, "correlation_id", correlationID)
+	log := logger.WithName("Reconcile")
+	var err error
+
+	bkp := &db.MongoDBBackup{}
+	if err = r.Get(ctx, req.NamespacedName, bkp); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("MongoDBBackup resource not found. Ignore since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "failed to get MongoDBBackup")
+		return ctrl.Result{}, err
+	}
+
+	switch bkp.Status.Phase {
+	case db.BackupPhaseSucceeded, db.BackupPhaseFailed:
+		if bkp.Status.FsyncLock == nil {
+			return ctrl.Result{}, nil
+		}
+		// the unlock failed on the completion of the backup
+		if err = backup.Unlock(ctx, r.Client, bkp); err != nil {
+			log.Error(err, "unlock failed")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.UpdateStatus(ctx, bkp, bkp.Status.Phase, bkp.Status.Message)
+	case db.BackupPhaseRunning:
+		return r.checkSnapshot(ctx, bkp)
+	case "":
+		now := metav1.Now()
+		bkp.Status.StartTime = &now
+		if err = r.UpdateStatus(ctx, bkp, db.BackupPhasePending, ""); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// a previous attempt stopped with the member locked
+	if err = backup.Unlock(ctx, r.Client, bkp); err != nil {
+		log.Error(err, "unlock failed")
+		return ctrl.Result{}, err
+	}
+	mdb, err := backup.GetMongoDB(ctx, r.Client, bkp)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, "MongoDB instance not found")
+		}
+		return ctrl.Result{}, err
+	}
+	if mdb.Status.Phase != db.MongoDBPhaseReady {
+		log.V(1).Info("waiting for the instance to be ready")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	if bkp.Status.SecretName, err = backup.CreateSecret(ctx, r.Client, r.Scheme, bkp, mdb); err != nil {
+		log.Error(err, "backup secret processing failed")
+		return ctrl.Result{}, err
+	}
+	if err = backup.SetMember(ctx, r.Client, bkp, mdb); err != nil {
+		log.Error(err, "backup member processing failed")
+		return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, err.Error())
+	}
+	if err = r.UpdateStatus(ctx, bkp, db.BackupPhasePending, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = backup.Snapshot(ctx, r.Client, r.Scheme, bkp, mdb); err != nil {
+		log.Error(err, "snapshot failed")
+		if err := backup.Unlock(ctx, r.Client, bkp); err != nil {
+			log.Error(err, "unlock failed")
+		}
+		return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, err.Error())
+	}
+	if err = r.UpdateStatus(ctx, bkp, db.BackupPhaseRunning, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.getSnapshotPeriod(bkp)}, nil
+}
+
+// checkSnapshot completes the backup once the snapshot is ready to use
+func (r *MongoDBBackupReconciler) checkSnapshot(ctx context.Context, bkp *db.MongoDBBackup) (ctrl.Result, error) {
+	log := util.GetLog(ctx, bkp).WithName("CheckSnapshot")
+	status, err := snapshot.GetStatus(ctx, r.Client, bkp.Namespace, bkp.Status.Snapshot)
+	if err != nil {
+		log.Error(err, "get snapshot status failed")
+		if e, ok := err.(*snapshot.Error); ok && errors.IsNotFound(e.Cause) {
+			if err := backup.Unlock(ctx, r.Client, bkp); err != nil {
+				log.Error(err, "unlock failed")
+			}
+			return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, "snapshot not found")
+		}
+		return ctrl.Result{}, err
+	}
+	if bkp.Status.FsyncLock != nil {
+		cut, cutErr := backup.IsCut(bkp, status, time.Now())
+		if !cut && cutErr == nil {
+			log.V(1).Info("waiting for the snapshot to be cut")
+			return ctrl.Result{RequeueAfter: r.getSnapshotPeriod(bkp)}, nil
+		}
+		if err := backup.Unlock(ctx, r.Client, bkp); err != nil {
+			log.Error(err, "unlock failed")
+			return ctrl.Result{}, err
+		}
+		if cutErr != nil {
+			return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, cutErr.Error())
+		}
+		if err := r.UpdateStatus(ctx, bkp, db.BackupPhaseRunning, ""); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	switch {
+	case status.Error != "":
+		return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseFailed, status.Error)
+	case status.ReadyToUse:
+		now := metav1.Now()
+		bkp.Status.CompletionTime = &now
+		return ctrl.Result{}, r.UpdateStatus(ctx, bkp, db.BackupPhaseSucceeded, "")
+	}
+	log.V(1).Info("waiting for the snapshot to be ready")
+	return ctrl.Result{RequeueAfter: r.getSnapshotPeriod(bkp)}, nil
+}
+
+// getSnapshotPeriod return the period the snapshot is checked at, shorter while the member is locked
+func (r *MongoDBBackupReconciler) getSnapshotPeriod(bkp *db.MongoDBBackup) time.Duration {
+	if bkp.Status.FsyncLock != nil {
+		return 2 * time.Second
+	}
+	return 10 * time.Second
+}
+
+// UpdateStatus set the phase of the backup
+func (r *MongoDBBackupReconciler) UpdateStatus(ctx context.Context, bkp *db.MongoDBBackup, phase db.BackupPhase, message string) error {
+	log := util.GetLog(ctx, bkp)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		bkp.Status.Phase = phase
+		bkp.Status.Message = message
+		if err := r.Status().Update(ctx, bkp); err != nil {
+			log.Error(err, "unable to update MongoDBBackup status (retry)")
+			return err
+		}
+		return nil
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MongoDBBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&db.MongoDBBackup{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 10,
+		}).
+		Complete(r)
+}
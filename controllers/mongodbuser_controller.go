@@ -18,33 +18,45 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/w6d-io/mongodb/internal/config"
 	"github.com/w6d-io/mongodb/internal/util"
 	"github.com/w6d-io/mongodb/pkg/controllers/mongodb/user"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 // MongoDBUserReconciler reconciles a MongoDBUser object
 type MongoDBUserReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbusers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbusers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=db.w6d.io,resources=mongodbusers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 func (r *MongoDBUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	correlationID := uuid.New().String()
@@ -67,8 +79,12 @@ func (r *MongoDBUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if usr.GetDeletionTimestamp() != nil {
-		if controllerutil.ContainsFinalizer(usr, FinalizerName) {
-			if err = user.Delete(ctx, r.Client, usr); err != nil && !errors.IsNotFound(err) {
+		if controllerutil.ContainsFinalizer(usr, FinalizerName) && r.isInstanceMissing(ctx, usr) {
+			log.Info("MongoDB instance not found, release the user")
+			r.Recorder.Eventf(usr, corev1.EventTypeWarning, "InstanceNotFound",
+				"MongoDB %s not found, the user is released without being dropped", usr.Spec.DBRef.Name)
+		} else if controllerutil.ContainsFinalizer(usr, FinalizerName) {
+			if err = r.drop(ctx, usr); err != nil && !errors.IsNotFound(err) {
 				log.Error(err, "delete MongoDB user failed")
 				return ctrl.Result{}, err
 			}
@@ -90,7 +106,58 @@ func (r *MongoDBUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
-	if err = user.Create(ctx, r.Client, usr); err != nil {
+	c, err := user.GetClient(ctx, r.Client, usr)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		if err = r.UpdateStatus(ctx, usr, db.MongoDBUserFailed); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	adoption, err := user.Adopt(ctx, r.Client, c, usr)
+	if err != nil {
+		log.Error(err, "adopt MongoDB user")
+		if err = r.UpdateStatus(ctx, usr, db.MongoDBUserFailed); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+	if adoption != nil {
+		usr.Status.Adoption = adoption
+		previous := adoption.PreviousOwner
+		if previous == "" {
+			previous = "none"
+		}
+		r.Recorder.Eventf(usr, corev1.EventTypeNormal, "Adopted",
+			"user %s adopted with policy %s, previous owner %s", usr.Spec.Username, usr.Spec.GetAdoptionPolicy(), previous)
+	}
+	// the user is compared with the database once created from the current spec and password,
+	// a change of either of them is not a drift
+	passwordVersion := r.getPasswordVersion(ctx, usr)
+	resync := usr.Status.Status == db.MongoDBUSerCreated && usr.Status.ObservedGeneration == usr.Generation &&
+		usr.Status.PasswordVersion == passwordVersion
+	var drift user.Drift
+	if resync {
+		if drift, err = user.GetDrift(ctx, r.Client, c, usr); err != nil {
+			log.Error(err, "check MongoDB user drift")
+			if err = r.UpdateStatus(ctx, usr, db.MongoDBUserFailed); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	// the user is not written again when it matches its spec
+	if resync && drift.IsZero() && !drift.Stale {
+		r.setDrifted(usr, drift)
+		if err = r.UpdateStatus(ctx, usr, db.MongoDBUSerCreated); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: config.GetUserResyncPeriod()}, nil
+	}
+	if err = user.Create(ctx, r.Client, c, usr); err != nil {
 		// TODO: if err returned is a non exist maybe return nil
 		log.Error(err, "create MongoDB user")
 		if err = r.UpdateStatus(ctx, usr, db.MongoDBUserFailed); err != nil {
@@ -98,10 +165,68 @@ func (r *MongoDBUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 		return ctrl.Result{}, err
 	}
+	if resync {
+		r.setDrifted(usr, drift)
+	}
+	usr.Status.ObservedGeneration = usr.Generation
+	usr.Status.PasswordVersion = passwordVersion
 	if err = r.UpdateStatus(ctx, usr, db.MongoDBUSerCreated); err != nil {
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: config.GetUserResyncPeriod()}, nil
+}
+
+// setDrifted reports the changes made by hand in the database that have been reverted
+func (r *MongoDBUserReconciler) setDrifted(usr *db.MongoDBUser, drift user.Drift) {
+	condition := metav1.Condition{
+		Type:               db.ConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "InSync",
+		Message:            "user matches its spec",
+		ObservedGeneration: usr.Generation,
+	}
+	if !drift.IsZero() {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "DriftCorrected"
+		condition.Message = fmt.Sprintf("%s changed in the database, reverted to the spec", drift)
+		r.Recorder.Eventf(usr, corev1.EventTypeWarning, condition.Reason,
+			"%s of user %s changed in the database, reverted to the spec", drift, usr.Spec.Username)
+	}
+	meta.SetStatusCondition(&usr.Status.Conditions, condition)
+}
+
+// drop deletes the user from the database
+func (r *MongoDBUserReconciler) drop(ctx context.Context, usr *db.MongoDBUser) error {
+	c, err := user.GetClient(ctx, r.Client, usr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	return user.Delete(ctx, r.Client, c, usr)
+}
+
+// getPasswordVersion return the resource version of the secret holding the password, empty for a raw value
+func (r *MongoDBUserReconciler) getPasswordVersion(ctx context.Context, usr *db.MongoDBUser) string {
+	from := usr.Spec.Password.ValueFrom
+	if from == nil || from.SecretKeyRef == nil {
+		return ""
+	}
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: from.SecretKeyRef.Name, Namespace: usr.Namespace}, s); err != nil {
+		return ""
+	}
+	return s.ResourceVersion
+}
+
+// isInstanceMissing return whether the instance referenced by the user does not exist anymore
+func (r *MongoDBUserReconciler) isInstanceMissing(ctx context.Context, usr *db.MongoDBUser) bool {
+	if usr.Spec.DBRef == nil {
+		return false
+	}
+	_, err := user.GetMongoDB(ctx, r.Client, usr)
+	return errors.IsNotFound(err)
 }
 
 // UpdateStatus set the status of user creation in mongodb
@@ -123,9 +248,31 @@ func (r *MongoDBUserReconciler) UpdateStatus(ctx context.Context, mdu *db.MongoD
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// The users are reconciled again when the secret holding their password changes, their instance becomes ready
+// or the addresses of the pods they authenticate from change
 func (r *MongoDBUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctx := context.Background()
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &db.MongoDBUser{}, UserSecretIndex, user.GetSecretNames); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &db.MongoDBUser{}, UserDBRefIndex, user.GetDBRefNames); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &db.MongoDBUser{}, UserClientSourceIndex, user.GetClientSourcesFrom); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&db.MongoDBUser{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(user.GetRequests(r.Client, UserSecretIndex, client.Object.GetName))).
+		Watches(&source.Kind{Type: &db.MongoDB{}},
+			handler.EnqueueRequestsFromMapFunc(user.GetRequests(r.Client, UserDBRefIndex, client.Object.GetName)),
+			builder.WithPredicates(user.BecomesReady())).
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(user.GetRequests(r.Client, UserClientSourceIndex, func(client.Object) string {
+				return string(db.ClientSourceFromNamespacePods)
+			})),
+			builder.WithPredicates(user.PodIPChanges())).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 10,
 		}).
@@ -16,6 +16,23 @@ Created on 10/04/2021
 */
 package controllers
 
+import "time"
+
 const (
 	FinalizerName string = "db.w6d.io/finalizer"
+
+	// RoleResyncPeriod is the period at which the replica set roles are polled
+	RoleResyncPeriod = 30 * time.Second
+
+	// ExpiryWarningPeriod is the time before the expiry of the instance a warning is emitted
+	ExpiryWarningPeriod = 15 * time.Minute
+
+	// UserSecretIndex indexes the users by the name of the secret holding their password
+	UserSecretIndex = ".spec.password.valueFrom.secretKeyRef.name"
+
+	// UserDBRefIndex indexes the users by the name of their instance
+	UserDBRefIndex = ".spec.dbref.name"
+
+	// UserClientSourceIndex indexes the users by the addresses of the cluster they authenticate from
+	UserClientSourceIndex = ".spec.authenticationRestrictions.clientSourceFrom"
 )
@@ -15,6 +15,7 @@ require (
 	github.com/onsi/gomega v1.10.2
 	github.com/prometheus/client_golang v1.9.0 // indirect
 	github.com/prometheus/common v0.19.0 // indirect
+	github.com/xdg-go/scram v1.0.2
 	go.mongodb.org/mongo-driver v1.5.1
 	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/zap v1.16.0
@@ -30,5 +31,7 @@ require (
 	k8s.io/apiextensions-apiserver v0.19.7 // indirect
 	k8s.io/apimachinery v0.19.7
 	k8s.io/client-go v0.19.7
+	k8s.io/component-base v0.19.7
 	sigs.k8s.io/controller-runtime v0.7.2
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -17,65 +17,342 @@ Created on 31/03/2021
 package config
 
 import (
+	"bytes"
+	"context"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
 
-	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
+	componentconfig "k8s.io/component-base/config/v1alpha1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 )
 
 // New get the filename and fill Config struct
 func New(filename string) error {
-	// TODO add dynamic configuration feature
 	log := ctrl.Log.WithName("controllers").WithName("Config")
 	log.V(1).Info("read config file")
-	config = new(Config)
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		log.Error(err, "error reading the configuration")
 		return err
 	}
-	if err := yaml.Unmarshal(data, config); err != nil {
+	c, m, err := parse(data)
+	if err != nil {
 		log.Error(err, "Error unmarshal the configuration")
 		return err
 	}
-	config.Namespace = os.Getenv("NAMESPACE")
-	if config.ServiceAccount.Name == "" {
-		config.ServiceAccount.Name = "default"
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+	manager = m
+	file = filename
+	content = data
+	return nil
+}
+
+// parse return the configuration of the operator and the ControllerManagerConfig held by the file
+func parse(data []byte) (*Config, *v1alpha1.ControllerManagerConfiguration, error) {
+	c := new(Config)
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, nil, err
+	}
+	c.Namespace = os.Getenv("NAMESPACE")
+	if c.ServiceAccount.Name == "" {
+		c.ServiceAccount.Name = "default"
+	}
+	m := new(v1alpha1.ControllerManagerConfiguration)
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, nil, err
 	}
+	return c, m, nil
+}
+
+// get return the current configuration, it is replaced as a whole on reload and never modified
+func get() *Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+// GetManagerOptions return the options completed by the ControllerManagerConfig of the file,
+// the options already set by the flags take precedence. They are only read at start
+func GetManagerOptions(options ctrl.Options) (ctrl.Options, error) {
+	mu.RLock()
+	m := manager.DeepCopy()
+	mu.RUnlock()
+	if m.LeaderElection == nil {
+		m.LeaderElection = &componentconfig.LeaderElectionConfiguration{}
+	}
+	return options.AndFrom(m)
+}
+
+// Watcher reloads the configuration when the file changes. It runs on every replica
+// since the webhooks read the configuration too
+type Watcher struct{}
+
+// Start polls the file until the context is done, an invalid file is logged and ignored
+func (Watcher) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(context.Context) { reload() }, WatchPeriod)
 	return nil
 }
 
+// NeedLeaderElection implements LeaderElectionRunnable so that the watcher runs without the lease
+func (Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// reload replaces the configuration when the content of the file changed
+func reload() {
+	log := ctrl.Log.WithName("controllers").WithName("Config")
+	mu.RLock()
+	filename, current := file, content
+	mu.RUnlock()
+	if filename == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Error(err, "error reading the configuration")
+		return
+	}
+	if bytes.Equal(data, current) {
+		return
+	}
+	c, m, err := parse(data)
+	if err != nil {
+		log.Error(err, "invalid configuration, the current one is kept")
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+	manager = m
+	content = data
+	log.Info("configuration reloaded")
+}
+
 // GetNamespace return the namespace
 func GetNamespace() string {
-	return config.Namespace
+	return get().Namespace
+}
+
+// GetWatchNamespaces return the namespaces watched by the operator from the comma separated list of the flag,
+// or from the configuration file when empty. None is returned when every namespace is watched, otherwise
+// the namespace of the operator is added since the secrets referenced without namespace are read from it
+func GetWatchNamespaces(list string) []string {
+	c := get()
+	candidates := c.WatchNamespaces
+	if list != "" {
+		candidates = strings.Split(list, ",")
+	}
+	var namespaces []string
+	for _, namespace := range candidates {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) != 0 && c.Namespace != "" && !contains(namespaces, c.Namespace) {
+		namespaces = append(namespaces, c.Namespace)
+	}
+	return namespaces
+}
+
+func contains(list []string, value string) bool {
+	for _, elem := range list {
+		if elem == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserResyncPeriod return the period the users are compared with the database, zero when disabled
+func GetUserResyncPeriod() time.Duration {
+	c := get()
+	if c.UserResyncPeriod == nil {
+		return DefaultUserResyncPeriod
+	}
+	return c.UserResyncPeriod.Duration
+}
+
+// IsAdoptionPolicyAllowed return whether the users may set the adoption policy
+func IsAdoptionPolicyAllowed(policy string) bool {
+	policies := get().AdoptionPolicies
+	return policy == "Fail" || len(policies) == 0 || contains(policies, policy)
+}
+
+// GetAdoptionPolicies return the adoption policies the users may set
+func GetAdoptionPolicies() []string {
+	policies := get().AdoptionPolicies
+	if len(policies) == 0 {
+		return []string{"Fail", "Adopt", "AdoptIfNoOwner"}
+	}
+	if contains(policies, "Fail") {
+		return append([]string{}, policies...)
+	}
+	return append([]string{"Fail"}, policies...)
+}
+
+// GetPodCIDRs return the pod CIDR ranges of the cluster set in the configuration
+func GetPodCIDRs() []string {
+	return append([]string{}, get().PodCIDRs...)
 }
 
 // GetServiceAccountName return the Service Account Name
 func GetServiceAccountName() string {
-	return config.ServiceAccount.Name
+	return get().ServiceAccount.Name
 }
 
 func GetImage(key string) string {
-	return string(config.Images[key])
+	return GetMirror(string(get().Images[key]))
+}
+
+// GetVersion return the images of the version from the catalog with the registry mirrors applied
+func GetVersion(version string) (Version, bool) {
+	v, ok := get().Versions[version]
+	if !ok {
+		return Version{}, false
+	}
+	v.MongoDB = Image(GetMirror(string(v.MongoDB)))
+	v.Metrics = Image(GetMirror(string(v.Metrics)))
+	v.Tools = Image(GetMirror(string(v.Tools)))
+	return v, true
+}
+
+// GetVersions return the sorted versions of the catalog
+func GetVersions() []string {
+	var versions []string
+	for version := range get().Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// HasVersions return whether the supported versions are restricted by a catalog
+func HasVersions() bool {
+	return len(get().Versions) != 0
+}
+
+// GetImagePullSecrets return the pull secrets of every version followed by the ones of the version
+func GetImagePullSecrets(version string) []corev1.LocalObjectReference {
+	c := get()
+	secrets := append([]corev1.LocalObjectReference{}, c.ImagePullSecrets...)
+	secrets = append(secrets, c.Versions[version].ImagePullSecrets...)
+	if len(secrets) == 0 {
+		return nil
+	}
+	return secrets
+}
+
+// GetMirror return the image pulled from the mirror of its registry
+func GetMirror(image string) string {
+	mirrors := get().Mirrors
+	if image == "" || len(mirrors) == 0 {
+		return image
+	}
+	registry := DefaultRegistry
+	path := image
+	if i := strings.Index(image, "/"); i > 0 {
+		if host := image[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry = host
+			path = image[i+1:]
+		}
+	}
+	mirror, ok := mirrors[registry]
+	if !ok {
+		return image
+	}
+	if registry == DefaultRegistry && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+	return strings.TrimSuffix(mirror, "/") + "/" + path
 }
 
+// GetSecurityContext return the pod-level securityContext from the configuration
+// or a default compliant with the restricted Pod Security Standard
 func GetSecurityContext() *corev1.PodSecurityContext {
-	if config.SecurityContext == nil {
-		return &corev1.PodSecurityContext{}
+	c := get()
+	if c.SecurityContext != nil {
+		return c.SecurityContext.DeepCopy()
+	}
+	nonRoot := true
+	sc := &corev1.PodSecurityContext{
+		RunAsNonRoot: &nonRoot,
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
 	}
-	return config.SecurityContext
+	if !c.OpenShift {
+		runAsUser := DefaultRunAsUser
+		fsGroup := DefaultRunAsUser
+		sc.RunAsUser = &runAsUser
+		sc.FSGroup = &fsGroup
+	}
+	return sc
+}
+
+// GetContainerSecurityContext return the container securityContext from the configuration
+// or a default compliant with the restricted Pod Security Standard
+func GetContainerSecurityContext() *corev1.SecurityContext {
+	c := get()
+	if c.ContainerSecurityContext != nil {
+		return c.ContainerSecurityContext.DeepCopy()
+	}
+	nonRoot := true
+	readOnly := true
+	privilegeEscalation := false
+	sc := &corev1.SecurityContext{
+		RunAsNonRoot:             &nonRoot,
+		ReadOnlyRootFilesystem:   &readOnly,
+		AllowPrivilegeEscalation: &privilegeEscalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	if !c.OpenShift {
+		runAsUser := DefaultRunAsUser
+		sc.RunAsUser = &runAsUser
+	}
+	return sc
+}
+
+// IsOpenShift return whether the operator runs in OpenShift mode
+func IsOpenShift() bool {
+	return get().OpenShift
 }
 
 func GetNodeSelector() map[string]string {
-	return config.NodeSelector
+	nodeSelector := get().NodeSelector
+	if nodeSelector == nil {
+		return nil
+	}
+	ns := make(map[string]string, len(nodeSelector))
+	for key, value := range nodeSelector {
+		ns[key] = value
+	}
+	return ns
 }
 
 func GetAffinity() *corev1.Affinity {
-	return config.Affinity
+	return get().Affinity.DeepCopy()
 }
 
 func GetTolerations() []corev1.Toleration {
-	return config.Tolerations
+	tolerations := get().Tolerations
+	if tolerations == nil {
+		return nil
+	}
+	return append([]corev1.Toleration{}, tolerations...)
 }
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Config", func() {
+	var filename string
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "config")
+		Expect(err).ToNot(HaveOccurred())
+		filename = filepath.Join(dir, "config.yaml")
+		Expect(ioutil.WriteFile(filename, []byte(`apiVersion: controller-runtime.sigs.k8s.io/v1alpha1
+kind: ControllerManagerConfig
+webhook:
+  port: 9444
+leaderElection:
+  leaderElect: true
+  resourceName: test.w6d.io
+images:
+  metrics: 'bitnami/mongodb-exporter:0.11.2'
+tolerations:
+- key: dedicated
+  operator: Equal
+  value: mongodb
+`), 0600)).To(Succeed())
+		Expect(New(filename)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(filepath.Dir(filename))).To(Succeed())
+	})
+	Context("load", func() {
+		It("reads the operator settings", func() {
+			Expect(GetImage("metrics")).To(Equal("bitnami/mongodb-exporter:0.11.2"))
+			Expect(GetServiceAccountName()).To(Equal("default"))
+			Expect(GetTolerations()).To(HaveLen(1))
+			Expect(GetTolerations()[0].Value).To(Equal("mongodb"))
+		})
+		It("completes the manager options", func() {
+			options, err := GetManagerOptions(ctrl.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options.Port).To(Equal(9444))
+			Expect(options.LeaderElection).To(BeTrue())
+			Expect(options.LeaderElectionID).To(Equal("test.w6d.io"))
+		})
+		It("keeps the options set by the flags", func() {
+			options, err := GetManagerOptions(ctrl.Options{Port: 9443})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options.Port).To(Equal(9443))
+		})
+	})
+	Context("reload", func() {
+		It("applies the changes of the file", func() {
+			Expect(ioutil.WriteFile(filename, []byte(`images:
+  metrics: 'bitnami/mongodb-exporter:0.20.0'
+mirrors:
+  docker.io: registry.example.com/docker.io
+`), 0600)).To(Succeed())
+			reload()
+			Expect(GetImage("metrics")).To(Equal("registry.example.com/docker.io/bitnami/mongodb-exporter:0.20.0"))
+			Expect(GetTolerations()).To(BeEmpty())
+		})
+		It("keeps the configuration when the file is invalid", func() {
+			Expect(ioutil.WriteFile(filename, []byte("images: ["), 0600)).To(Succeed())
+			reload()
+			Expect(GetImage("metrics")).To(Equal("bitnami/mongodb-exporter:0.11.2"))
+		})
+	})
+	Context("watch namespaces", func() {
+		It("watches every namespace by default", func() {
+			Expect(GetWatchNamespaces("")).To(BeEmpty())
+		})
+		It("adds the namespace of the operator to the namespaces of the flag", func() {
+			config = &Config{Namespace: "k8sdb", WatchNamespaces: []string{"team-c"}}
+			Expect(GetWatchNamespaces("team-a, team-b,team-a")).To(Equal([]string{"team-a", "team-b", "k8sdb"}))
+			Expect(GetWatchNamespaces("")).To(Equal([]string{"team-c", "k8sdb"}))
+		})
+	})
+	Context("user resync", func() {
+		It("resyncs the users every 5 minutes by default", func() {
+			Expect(GetUserResyncPeriod()).To(Equal(DefaultUserResyncPeriod))
+		})
+		It("reads the period of the file", func() {
+			Expect(ioutil.WriteFile(filename, []byte("userResyncPeriod: 1m30s\n"), 0600)).To(Succeed())
+			reload()
+			Expect(GetUserResyncPeriod()).To(Equal(90 * time.Second))
+			Expect(ioutil.WriteFile(filename, []byte("userResyncPeriod: 0s\n"), 0600)).To(Succeed())
+			reload()
+			Expect(GetUserResyncPeriod()).To(BeZero())
+		})
+	})
+	Context("adoption policies", func() {
+		It("allows every policy by default", func() {
+			Expect(IsAdoptionPolicyAllowed("Adopt")).To(BeTrue())
+			Expect(GetAdoptionPolicies()).To(Equal([]string{"Fail", "Adopt", "AdoptIfNoOwner"}))
+		})
+		It("restricts the policies to the configured ones", func() {
+			config = &Config{AdoptionPolicies: []string{"AdoptIfNoOwner"}}
+			Expect(IsAdoptionPolicyAllowed("Fail")).To(BeTrue())
+			Expect(IsAdoptionPolicyAllowed("AdoptIfNoOwner")).To(BeTrue())
+			Expect(IsAdoptionPolicyAllowed("Adopt")).To(BeFalse())
+			Expect(GetAdoptionPolicies()).To(Equal([]string{"Fail", "AdoptIfNoOwner"}))
+		})
+	})
+	Context("mirrors", func() {
+		It("pulls the images from the mirror of their registry", func() {
+			config = &Config{Mirrors: map[string]string{
+				"docker.io": "mirror.example.com/hub/",
+				"quay.io":   "mirror.example.com/quay",
+			}}
+			Expect(GetMirror("mongo:6.0")).To(Equal("mirror.example.com/hub/library/mongo:6.0"))
+			Expect(GetMirror("percona/percona-server-mongodb:6.0")).To(Equal("mirror.example.com/hub/percona/percona-server-mongodb:6.0"))
+			Expect(GetMirror("quay.io/org/image:1")).To(Equal("mirror.example.com/quay/org/image:1"))
+			Expect(GetMirror("ghcr.io/org/image:1")).To(Equal("ghcr.io/org/image:1"))
+		})
+	})
+})
@@ -16,7 +16,14 @@ Created on 31/03/2021
 */
 package config
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+)
 
 // Config for the controller
 type Config struct {
@@ -24,15 +31,36 @@ type Config struct {
 	// Namespace where controller running
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
+	// WatchNamespaces restricts the operator to the namespaces, every namespace is watched when empty
+	WatchNamespaces []string `json:"watchNamespaces,omitempty" yaml:"watchNamespaces,omitempty"`
+
 	// Images map of image to use for build resource (sts, jobs)
 	Images map[string]Image `json:"images,omitempty" yaml:"images,omitempty"`
 
+	// Versions is the catalog of the supported MongoDB versions and their images.
+	// Any version is accepted and run from the mongodb image when the catalog is empty
+	Versions map[string]Version `json:"versions,omitempty" yaml:"versions,omitempty"`
+
+	// ImagePullSecrets are set on the pods of every version
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty" yaml:"imagePullSecrets,omitempty"`
+
+	// Mirrors map of registry to the registry mirror the images are pulled from,
+	// the images without registry belong to docker.io
+	Mirrors map[string]string `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+
 	// ServiceAccount for the Pod
 	ServiceAccount corev1.LocalObjectReference `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
 
-	// SecurityContext is the default securityContext for the mongodb container
+	// SecurityContext is the default pod-level securityContext
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty" yaml:"securityContext,omitempty"`
 
+	// ContainerSecurityContext is the default securityContext for the containers
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty" yaml:"containerSecurityContext,omitempty"`
+
+	// OpenShift leaves runAsUser and fsGroup unset in the default security contexts
+	// so that they are assigned by the restricted SCC
+	OpenShift bool `json:"openshift,omitempty" yaml:"openshift,omitempty"`
+
 	// Affinity to applied to pods
 	Affinity *corev1.Affinity `json:"affinity,omitempty" yaml:"affinity,omitempty"`
 
@@ -41,10 +69,61 @@ type Config struct {
 
 	// Tolerations to set for pods
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+
+	// UserResyncPeriod is the period the users are compared with the database to revert the changes
+	// made by hand, 0 disables the resync. Default to 5m
+	UserResyncPeriod *metav1.Duration `json:"userResyncPeriod,omitempty" yaml:"userResyncPeriod,omitempty"`
+
+	// AdoptionPolicies restricts the adoption policies the users may set, Fail is always allowed.
+	// Every policy is allowed when empty
+	AdoptionPolicies []string `json:"adoptionPolicies,omitempty" yaml:"adoptionPolicies,omitempty"`
+
+	// PodCIDRs are the pod CIDR ranges of the cluster allowed by the PodCIDR client source of the users,
+	// read from the nodes when empty
+	PodCIDRs []string `json:"podCIDRs,omitempty" yaml:"podCIDRs,omitempty"`
 }
 
 type Image string
 
+// Version holds the images of a MongoDB version, they should be pinned by digest
+type Version struct {
+	// MongoDB is the image running mongod
+	MongoDB Image `json:"mongodb" yaml:"mongodb"`
+
+	// Metrics is the image of the exporter, the metrics image is used when empty
+	Metrics Image `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+
+	// Tools is the image of the init containers, the tls image is used when empty
+	Tools Image `json:"tools,omitempty" yaml:"tools,omitempty"`
+
+	// ImagePullSecrets are set on the pods of the version
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty" yaml:"imagePullSecrets,omitempty"`
+
+	// Flavor is the distribution of the images, Bitnami, Official or Percona, used by the instances
+	// of the version created without flavor
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+}
+
+const (
+	// DefaultRunAsUser is the uid used by the bitnami images
+	DefaultRunAsUser int64 = 1001
+
+	// DefaultRegistry is the registry of the images without registry
+	DefaultRegistry string = "docker.io"
+
+	// WatchPeriod is the period the configuration file is checked for changes
+	WatchPeriod = 10 * time.Second
+
+	// DefaultUserResyncPeriod is the period the users are resynced when the configuration does not set it
+	DefaultUserResyncPeriod = 5 * time.Minute
+)
+
 var (
-	config = &Config{}
+	// mu guards the configuration replaced on reload
+	mu      sync.RWMutex
+	config  = &Config{}
+	manager = &v1alpha1.ControllerManagerConfiguration{}
+	// file is the path of the configuration file and content its last loaded content
+	file    string
+	content []byte
 )
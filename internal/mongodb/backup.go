@@ -0,0 +1,111 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetBackupMember return the host of the member the backup is taken from. The hidden members are
+// preferred as they serve no reads, then the secondaries and the primary as a last resort
+func GetBackupMember(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (string, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetBackupMember")
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return "", err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	config, err := GetReplicaSetConfig(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set config failed")
+		return "", err
+	}
+	states, err := GetMemberStates(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set status failed")
+		return "", err
+	}
+	selected := selectBackupMember(config, states)
+	if selected == "" {
+		return "", errors.New("no member available for the backup")
+	}
+	return selected, nil
+}
+
+// selectBackupMember return the host of the member of the configuration with the best backup rank
+func selectBackupMember(config bson.M, states map[string]string) string {
+	members, _ := config["members"].(bson.A)
+	var selected string
+	rank := noBackupRank
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			continue
+		}
+		if current := getBackupRank(states[GetMemberPodName(member)], member["hidden"] == true); current < rank {
+			selected, _ = member["host"].(string)
+			rank = current
+		}
+	}
+	return selected
+}
+
+const noBackupRank = 3
+
+// getBackupRank return the preference of the member for the backup, the lowest first
+func getBackupRank(state string, hidden bool) int {
+	switch {
+	case state == "SECONDARY" && hidden:
+		return 0
+	case state == "SECONDARY":
+		return 1
+	case state == "PRIMARY":
+		return 2
+	}
+	return noBackupRank
+}
+
+// FsyncLock flushes the pending writes of the member to disk and locks it against writes
+func FsyncLock(ctx context.Context, c *mongo.Client) error {
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "fsync", Value: 1},
+		{Key: "lock", Value: true},
+	})
+	return res.Err()
+}
+
+// FsyncUnlock releases the lock taken by FsyncLock. The member no longer locked, restarted for instance, is not an error
+func FsyncUnlock(ctx context.Context, c *mongo.Client) error {
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "fsyncUnlock", Value: 1},
+	})
+	if e, ok := res.Err().(mongo.CommandError); ok && strings.Contains(e.Message, "not locked") {
+		return nil
+	}
+	return res.Err()
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var _ = Describe("Backup", func() {
+	Context("backup member", func() {
+		var config bson.M
+		BeforeEach(func() {
+			config = bson.M{"members": bson.A{
+				bson.M{"_id": int32(0), "host": "test-0.test-headless.default.svc.cluster.local:27017"},
+				bson.M{"_id": int32(1), "host": "test-1.test-headless.default.svc.cluster.local:27017"},
+				bson.M{"_id": int32(2), "host": "test-hidden-0.test-hidden-headless.default.svc.cluster.local:27017", "hidden": true},
+				bson.M{"_id": int32(3), "host": "test-arbiter-0.test-arbiter-headless.default.svc.cluster.local:27017", "arbiterOnly": true},
+			}}
+		})
+		It("prefers the hidden secondary", func() {
+			states := map[string]string{"test-0": "PRIMARY", "test-1": "SECONDARY", "test-hidden-0": "SECONDARY", "test-arbiter-0": "ARBITER"}
+			Expect(selectBackupMember(config, states)).To(Equal("test-hidden-0.test-hidden-headless.default.svc.cluster.local:27017"))
+		})
+		It("falls back on a secondary", func() {
+			states := map[string]string{"test-0": "PRIMARY", "test-1": "SECONDARY", "test-hidden-0": "RECOVERING", "test-arbiter-0": "ARBITER"}
+			Expect(selectBackupMember(config, states)).To(Equal("test-1.test-headless.default.svc.cluster.local:27017"))
+		})
+		It("falls back on the primary", func() {
+			states := map[string]string{"test-0": "PRIMARY", "test-1": "STARTUP2", "test-arbiter-0": "ARBITER"}
+			Expect(selectBackupMember(config, states)).To(Equal("test-0.test-headless.default.svc.cluster.local:27017"))
+		})
+		It("returns no member when none holds the data", func() {
+			states := map[string]string{"test-0": "RECOVERING", "test-arbiter-0": "ARBITER"}
+			Expect(selectBackupMember(config, states)).To(BeEmpty())
+		})
+	})
+})
@@ -18,11 +18,16 @@ package mongodb
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
 	"github.com/w6d-io/mongodb/internal/util"
 	"github.com/w6d-io/mongodb/pkg/k8s/secret"
+	"github.com/xdg-go/scram"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,20 +36,92 @@ import (
 func GetClient(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (*mongo.Client, error) {
 	log := util.GetLog(ctx, mongoDB).WithName("GetClient")
 	log.V(1).Info("create MongoDB client")
+	URL := fmt.Sprintf("mongodb://%s", GetService(mongoDB))
+	return connect(ctx, r, mongoDB, options.Client().ApplyURI(URL))
+}
+
+// GetMemberClient return a client connected to the member only, whatever its state in the replica set
+func GetMemberClient(ctx context.Context, r client.Client, mongoDB *db.MongoDB, host string) (*mongo.Client, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetMemberClient")
+	log.V(1).Info("create MongoDB member client", "host", host)
+	URL := fmt.Sprintf("mongodb://%s", host)
+	return connect(ctx, r, mongoDB, options.Client().ApplyURI(URL).SetDirect(true))
+}
+
+// GetURIClient return a client connected through the connection string, an address forwarded from outside
+// of the cluster for instance, with the root credentials of the instance
+func GetURIClient(ctx context.Context, r client.Client, mongoDB *db.MongoDB, uri string) (*mongo.Client, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetURIClient")
+	log.V(1).Info("create MongoDB client from connection string")
+	return connect(ctx, r, mongoDB, options.Client().ApplyURI(uri))
+}
+
+func connect(ctx context.Context, r client.Client, mongoDB *db.MongoDB, opts *options.ClientOptions) (*mongo.Client, error) {
 	name := GetSecretName(mongoDB)
 	password := secret.GetContentFromKey(ctx, r, name, secret.MongoRootPasswordKey)
 	credential := options.Credential{
 		Username: "root",
 		Password: password,
 	}
-	URL := fmt.Sprintf("mongodb://%s", GetService(mongoDB))
-	opts := options.Client().ApplyURI(URL).SetAuth(credential)
-	c, err := mongo.Connect(ctx, opts)
+	c, err := mongo.Connect(ctx, opts.SetAuth(credential))
 	if err != nil {
 		return nil, err
 	}
 	return c, nil
+}
 
+// IsPassword checks the password of the user against the credentials stored in the instance. The client
+// authenticates as root so that the authentication restrictions of the user do not apply
+func IsPassword(ctx context.Context, c *mongo.Client, username, password string) (bool, error) {
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "usersInfo", Value: bson.M{"user": username, "db": "admin"}},
+		{Key: "showCredentials", Value: true},
+	})
+	var response struct {
+		Users []struct {
+			Credentials map[string]Credential `bson:"credentials"`
+		} `bson:"users"`
+	}
+	if err := res.Decode(&response); err != nil {
+		return false, err
+	}
+	if len(response.Users) == 0 {
+		return false, nil
+	}
+	return isSameCredentials(username, password, response.Users[0].Credentials)
+}
+
+// Credential is the SCRAM credential of a user stored by the instance
+type Credential struct {
+	IterationCount int    `bson:"iterationCount"`
+	Salt           string `bson:"salt"`
+	StoredKey      string `bson:"storedKey"`
+	ServerKey      string `bson:"serverKey"`
+}
+
+// isSameCredentials return whether the password derives the stored key of the strongest mechanism of the credentials.
+// The SCRAM-SHA-1 password is the digest of the username and the password
+func isSameCredentials(username, password string, credentials map[string]Credential) (bool, error) {
+	hash := scram.SHA256
+	credential, ok := credentials["SCRAM-SHA-256"]
+	if !ok {
+		credential, ok = credentials["SCRAM-SHA-1"]
+		if !ok {
+			return false, fmt.Errorf("user %s has no SCRAM credentials", username)
+		}
+		digest := md5.Sum([]byte(username + ":mongo:" + password))
+		hash, password = scram.SHA1, hex.EncodeToString(digest[:])
+	}
+	salt, err := base64.StdEncoding.DecodeString(credential.Salt)
+	if err != nil {
+		return false, err
+	}
+	sc, err := hash.NewClient(username, password, "")
+	if err != nil {
+		return false, err
+	}
+	stored := sc.GetStoredCredentials(scram.KeyFactors{Salt: string(salt), Iters: credential.IterationCount})
+	return base64.StdEncoding.EncodeToString(stored.StoredKey) == credential.StoredKey, nil
 }
 
 // GetSecretName return the secret resource name
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MongoDB", func() {
+	Context("credentials", func() {
+		sha256 := Credential{
+			IterationCount: 4096,
+			Salt:           "W22ZaJ0SNY7soEsUEjb6gQ==",
+			StoredKey:      "WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=",
+		}
+		sha1 := Credential{
+			IterationCount: 4096,
+			Salt:           "QSXCR+Q6sek8bf92",
+			StoredKey:      "TQJLWkI33z/g0P3zZnPYaYZ4NnI=",
+		}
+		It("checks the password against the SCRAM-SHA-256 credential", func() {
+			credentials := map[string]Credential{"SCRAM-SHA-256": sha256, "SCRAM-SHA-1": {}}
+			Expect(isSameCredentials("user", "pencil", credentials)).To(BeTrue())
+			Expect(isSameCredentials("user", "pen", credentials)).To(BeFalse())
+		})
+		It("checks the password against the SCRAM-SHA-1 credential", func() {
+			credentials := map[string]Credential{"SCRAM-SHA-1": sha1}
+			Expect(isSameCredentials("user", "pencil", credentials)).To(BeTrue())
+			Expect(isSameCredentials("user", "pen", credentials)).To(BeFalse())
+		})
+		It("fails without SCRAM credentials", func() {
+			_, err := isSameCredentials("user", "pencil", map[string]Credential{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
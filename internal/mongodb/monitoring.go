@@ -0,0 +1,117 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/secret"
+	"go.mongodb.org/mongo-driver/bson"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreateUpdateMonitoringUser creates or updates the least-privilege user used by the metrics exporter
+func CreateUpdateMonitoringUser(ctx context.Context, r client.Client, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateUpdateMonitoringUser")
+	if !mongoDB.Spec.Monitoring.IsEnabled() {
+		return nil
+	}
+	log.V(1).Info("create or update monitoring user")
+	password := secret.GetContentFromKey(ctx, r, util.GetTypesNamespaceNamed(ctx, mongoDB).String(), secret.MongoMetricsPasswordKey)
+	if password == "" {
+		log.Error(nil, "metrics password cannot be empty")
+		return errors.New("metrics password cannot be empty")
+	}
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	d := c.Database("admin")
+	res := d.RunCommand(ctx, bson.D{
+		{Key: "usersInfo", Value: bson.M{"user": db.MongoDBMetricsUsername, "db": "admin"}},
+		{Key: "showCredentials", Value: true},
+	})
+	var response struct {
+		Users []struct {
+			Roles       []userRole            `bson:"roles"`
+			Credentials map[string]Credential `bson:"credentials"`
+		} `bson:"users"`
+	}
+	if err = res.Decode(&response); err != nil {
+		log.Error(err, "get monitoring user failed")
+		return err
+	}
+	command := "createUser"
+	if len(response.Users) != 0 {
+		user := response.Users[0]
+		same, err := isSameCredentials(db.MongoDBMetricsUsername, password, user.Credentials)
+		if err == nil && same && isSameUserRoles(user.Roles, monitoringRoles) {
+			return nil
+		}
+		command = "updateUser"
+	}
+	roles := bson.A{}
+	for _, role := range monitoringRoles {
+		roles = append(roles, bson.M{"role": role.Role, "db": role.DB})
+	}
+	res = d.RunCommand(ctx, bson.D{
+		{Key: command, Value: db.MongoDBMetricsUsername},
+		{Key: "pwd", Value: password},
+		{Key: "roles", Value: roles},
+	})
+	if res.Err() != nil {
+		log.Error(res.Err(), "create or update monitoring user failed", "command", command)
+		return res.Err()
+	}
+	return nil
+}
+
+// userRole is a role granted to a user
+type userRole struct {
+	Role string `bson:"role"`
+	DB   string `bson:"db"`
+}
+
+// monitoringRoles are the roles of the monitoring user
+var monitoringRoles = []userRole{
+	{Role: "clusterMonitor", DB: "admin"},
+	{Role: "read", DB: "local"},
+}
+
+// isSameUserRoles return whether the roles granted to the user are the wanted ones whatever their order
+func isSameUserRoles(roles, wanted []userRole) bool {
+	granted := make(map[userRole]bool)
+	for _, role := range roles {
+		granted[role] = true
+	}
+	if len(granted) != len(wanted) {
+		return false
+	}
+	for _, role := range wanted {
+		if !granted[role] {
+			return false
+		}
+	}
+	return true
+}
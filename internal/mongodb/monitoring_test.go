@@ -0,0 +1,51 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Monitoring", func() {
+	Context("monitoring user roles", func() {
+		It("matches the roles whatever their order", func() {
+			Expect(isSameUserRoles([]userRole{
+				{Role: "read", DB: "local"},
+				{Role: "clusterMonitor", DB: "admin"},
+			}, monitoringRoles)).To(BeTrue())
+		})
+		It("detects a missing role", func() {
+			Expect(isSameUserRoles([]userRole{
+				{Role: "clusterMonitor", DB: "admin"},
+			}, monitoringRoles)).To(BeFalse())
+		})
+		It("detects an extra role", func() {
+			Expect(isSameUserRoles([]userRole{
+				{Role: "clusterMonitor", DB: "admin"},
+				{Role: "read", DB: "local"},
+				{Role: "root", DB: "admin"},
+			}, monitoringRoles)).To(BeFalse())
+		})
+		It("detects a role granted on another database", func() {
+			Expect(isSameUserRoles([]userRole{
+				{Role: "clusterMonitor", DB: "admin"},
+				{Role: "read", DB: "admin"},
+			}, monitoringRoles)).To(BeFalse())
+		})
+	})
+})
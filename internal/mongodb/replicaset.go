@@ -0,0 +1,533 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetReplicaSetConfig return the current replica set configuration
+func GetReplicaSetConfig(ctx context.Context, c *mongo.Client) (bson.M, error) {
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "replSetGetConfig", Value: 1},
+	})
+	var response struct {
+		Config bson.M `bson:"config"`
+	}
+	if err := res.Decode(&response); err != nil {
+		return nil, err
+	}
+	if response.Config == nil {
+		return nil, errors.New("replica set config is empty")
+	}
+	return response.Config, nil
+}
+
+// ReconfigReplicaSet increments the version of the configuration and applies it
+func ReconfigReplicaSet(ctx context.Context, c *mongo.Client, config bson.M) error {
+	switch version := config["version"].(type) {
+	case int32:
+		config["version"] = version + 1
+	case int64:
+		config["version"] = version + 1
+	case float64:
+		config["version"] = version + 1
+	}
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "replSetReconfig", Value: config},
+	})
+	return res.Err()
+}
+
+// GetMemberPodName return the pod name from the member host
+func GetMemberPodName(member bson.M) string {
+	host, _ := member["host"].(string)
+	host = strings.Split(host, ":")[0]
+	return strings.Split(host, ".")[0]
+}
+
+// SetHorizons sets the external horizon of the members from the pod name to address map.
+// All members of a replica set must declare the same horizons, so the members missing from
+// the map announce their own host. The horizons are removed when the map is empty
+func SetHorizons(ctx context.Context, r client.Client, mongoDB *db.MongoDB, addresses map[string]string) error {
+	log := util.GetLog(ctx, mongoDB).WithName("SetHorizons")
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	config, err := GetReplicaSetConfig(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set config failed")
+		return err
+	}
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return errors.New("replica set config has no members")
+	}
+	changed := false
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			continue
+		}
+		horizons, _ := member["horizons"].(bson.M)
+		address, wanted := addresses[GetMemberPodName(member)]
+		if !wanted && len(addresses) != 0 {
+			address, _ = member["host"].(string)
+			wanted = true
+		}
+		switch {
+		case wanted && (horizons == nil || horizons[db.MongoDBExternalHorizon] != address):
+			member["horizons"] = bson.M{db.MongoDBExternalHorizon: address}
+			changed = true
+		case !wanted && horizons != nil:
+			delete(member, "horizons")
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	log.V(1).Info("reconfigure replica set horizons", "horizons", addresses)
+	if err = ReconfigReplicaSet(ctx, c, config); err != nil {
+		log.Error(err, "replica set reconfig failed")
+		return err
+	}
+	return nil
+}
+
+// SetMembers adds the started members missing from the replica set configuration, the arbiter and hidden ones
+// and the data-bearing ones not joined by the Bitnami image, removes the ones no longer requested, the data-bearing
+// ones beyond the replicas included, and applies the settings of the data-bearing members.
+// A single voting member is added, removed or changed by reconfiguration, the returned boolean
+// reports whether changes are still pending
+func SetMembers(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (bool, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("SetMembers")
+	wanted := make(map[string]bson.M)
+	started := make(map[string]bool)
+	data := make(map[string]bson.M)
+	for i := 0; i < int(*statefulset.GetReplicas(mongoDB, statefulset.DataMember)); i++ {
+		data[statefulset.GetHost(mongoDB, statefulset.DataMember, i)] = getDataMemberSettings(mongoDB, i)
+	}
+	kinds := []string{statefulset.ArbiterMember, statefulset.HiddenMember}
+	if mongoDB.Spec.DataSource != nil || mongoDB.Spec.GetFlavor() != db.FlavorBitnami {
+		// the members started on restored data skip the initialization joining them to the replica set,
+		// the official and Percona images never join it
+		kinds = append(kinds, statefulset.DataMember)
+	}
+	for _, member := range kinds {
+		for i := 0; i < int(*statefulset.GetReplicas(mongoDB, member)); i++ {
+			host := statefulset.GetHost(mongoDB, member, i)
+			if member == statefulset.DataMember {
+				wanted[host] = data[host]
+			} else {
+				wanted[host] = getMemberSettings(member)
+			}
+			po := &corev1.Pod{}
+			name := fmt.Sprintf("%s-%d", statefulset.GetName(mongoDB, member), i)
+			err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: mongoDB.Namespace}, po)
+			if err != nil && !apierrors.IsNotFound(err) {
+				log.Error(err, "get pod failed", "pod", name)
+				return false, err
+			}
+			// the members are only ready once they hold a state in the replica set
+			started[host] = err == nil && isPodStarted(po)
+		}
+	}
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return false, err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	config, err := GetReplicaSetConfig(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set config failed")
+		return false, err
+	}
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return false, errors.New("replica set config has no members")
+	}
+
+	pending := false
+	voting := false
+	changed := false
+	current := make(map[string]bool)
+	var id int64
+	var kept bson.A
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+		host, _ := member["host"].(string)
+		if memberID := toInt64(member["_id"]); memberID > id {
+			id = memberID
+		}
+		settings, isWanted := wanted[host]
+		if !isWanted && isManagedMember(mongoDB, GetMemberPodName(member)) {
+			if voting {
+				pending = true
+				kept = append(kept, member)
+				continue
+			}
+			log.V(1).Info("remove member", "host", host)
+			voting = true
+			changed = true
+			continue
+		}
+		current[host] = true
+		if !isWanted {
+			settings = data[host]
+		}
+		if votes, ok := settings["votes"]; ok && !isSameValue(member["votes"], votes) {
+			if voting {
+				// the other settings may depend on the votes, they are applied together
+				pending = true
+				kept = append(kept, member)
+				continue
+			}
+			voting = true
+		}
+		for key, value := range settings {
+			if key != "arbiterOnly" && !isSameValue(member[key], value) {
+				log.V(1).Info("update member", "host", host, "setting", key)
+				member[key] = value
+				changed = true
+			}
+		}
+		kept = append(kept, member)
+	}
+	for _, host := range getSortedHosts(wanted) {
+		if current[host] {
+			continue
+		}
+		if voting || !started[host] {
+			pending = true
+			continue
+		}
+		log.V(1).Info("add member", "host", host)
+		id++
+		member := bson.M{"_id": id, "host": host}
+		for key, value := range wanted[host] {
+			member[key] = value
+		}
+		kept = append(kept, member)
+		voting = true
+		changed = true
+	}
+	if !changed {
+		return pending, nil
+	}
+	config["members"] = kept
+	if err = ReconfigReplicaSet(ctx, c, config); err != nil {
+		log.Error(err, "replica set reconfig failed")
+		return pending, err
+	}
+	return pending, nil
+}
+
+// HasReadyMember return whether a data-bearing member holds its state in the replica set, the readiness probe
+// reports the primary and the secondaries only. From then on the other members are joined by SetMembers, the
+// members started by the official and Percona images or on restored data never become ready before
+func HasReadyMember(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (bool, error) {
+	if *statefulset.GetReplicas(mongoDB, statefulset.DataMember) == 0 {
+		return false, nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mongoDB.Namespace),
+		client.MatchingLabels(statefulset.GetLabels(mongoDB, statefulset.DataMember))); err != nil {
+		return false, err
+	}
+	for i := range pods.Items {
+		if isPodReady(&pods.Items[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RestoreReplicaSet replaces the configuration restored from the data source, holding the members of
+// the source instance, by the first data-bearing member of the instance. The restored members cannot
+// elect a primary so the configuration is forced on the first member, the others are added by SetMembers.
+// It returns whether the configuration holds the members of the instance
+func RestoreReplicaSet(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (bool, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("RestoreReplicaSet")
+	if mongoDB.Spec.DataSource == nil {
+		return true, nil
+	}
+	po := &corev1.Pod{}
+	name := fmt.Sprintf("%s-0", statefulset.GetName(mongoDB, statefulset.DataMember))
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: mongoDB.Namespace}, po); err != nil || !isPodStarted(po) {
+		return false, client.IgnoreNotFound(err)
+	}
+	host := statefulset.GetHost(mongoDB, statefulset.DataMember, 0)
+	c, err := GetMemberClient(ctx, r, mongoDB, host)
+	if err != nil {
+		log.Error(err, "get MongoDB member client")
+		return false, err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	config, err := GetReplicaSetConfig(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set config failed")
+		return false, err
+	}
+	restored, err := setRestoredMembers(mongoDB, config)
+	if err != nil || !restored {
+		return err == nil, err
+	}
+	log.Info("force the replica set configuration restored from the data source", "host", host)
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "replSetReconfig", Value: config},
+		{Key: "force", Value: true},
+	})
+	if res.Err() != nil {
+		log.Error(res.Err(), "forced replica set reconfig failed")
+		return false, res.Err()
+	}
+	return true, nil
+}
+
+// setRestoredMembers replaces the members of the configuration by the first data-bearing member of the
+// instance when a member belongs to another instance. It returns whether the members were replaced
+func setRestoredMembers(mongoDB *db.MongoDB, config bson.M) (bool, error) {
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return false, errors.New("replica set config has no members")
+	}
+	restored := false
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			continue
+		}
+		if h, _ := member["host"].(string); !isInstanceHost(mongoDB, h) {
+			restored = true
+			break
+		}
+	}
+	if restored {
+		config["members"] = bson.A{bson.M{"_id": int32(0), "host": statefulset.GetHost(mongoDB, statefulset.DataMember, 0)}}
+	}
+	return restored, nil
+}
+
+// isInstanceHost return whether the host is the host of a member of the instance
+func isInstanceHost(mongoDB *db.MongoDB, host string) bool {
+	podName := GetMemberPodName(bson.M{"host": host})
+	for _, member := range statefulset.Members {
+		index, err := strconv.Atoi(strings.TrimPrefix(podName, statefulset.GetName(mongoDB, member)+"-"))
+		if err == nil && host == statefulset.GetHost(mongoDB, member, index) {
+			return true
+		}
+	}
+	return false
+}
+
+// getMemberSettings return the replica set member settings of the kind of member
+func getMemberSettings(member string) bson.M {
+	if member == statefulset.ArbiterMember {
+		return bson.M{"arbiterOnly": true}
+	}
+	return bson.M{"hidden": true, "priority": int32(0)}
+}
+
+// getDataMemberSettings return the replica set settings of the data-bearing member from the spec.
+// The settings missing from the spec are reset to the MongoDB defaults
+func getDataMemberSettings(mongoDB *db.MongoDB, index int) bson.M {
+	delayKey := "secondaryDelaySecs"
+	if util.GetMajorVersion(mongoDB.Spec.Version) < 5 {
+		delayKey = "slaveDelay"
+	}
+	settings := bson.M{
+		"priority": int32(1),
+		"votes":    int32(1),
+		delayKey:   int32(0),
+		"tags":     bson.M{},
+	}
+	for _, member := range mongoDB.Spec.Members {
+		if int(member.Index) != index {
+			continue
+		}
+		if member.Priority != nil {
+			settings["priority"] = *member.Priority
+		}
+		if member.Votes != nil {
+			settings["votes"] = *member.Votes
+		}
+		if member.SecondaryDelaySecs != nil {
+			settings[delayKey] = *member.SecondaryDelaySecs
+		}
+		tags := bson.M{}
+		for key, value := range member.Tags {
+			tags[key] = value
+		}
+		settings["tags"] = tags
+	}
+	return settings
+}
+
+// isManagedMember return whether the pod belongs to the arbiter or hidden members, or to the data-bearing
+// members removed by a scale down whatever the image joining them to the replica set
+func isManagedMember(mongoDB *db.MongoDB, podName string) bool {
+	for _, member := range []string{statefulset.ArbiterMember, statefulset.HiddenMember} {
+		if strings.HasPrefix(podName, statefulset.GetName(mongoDB, member)+"-") {
+			return true
+		}
+	}
+	// the replicas of the spec, the hibernation does not remove the members
+	prefix := statefulset.GetName(mongoDB, statefulset.DataMember) + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(podName, prefix))
+	return err == nil && index >= getRequestedReplicas(mongoDB, statefulset.DataMember)
+}
+
+// IsRemovedMember return whether the member of the replica set belongs to the instance but is no longer
+// requested by the spec, its pod is gone and SetMembers removes it from the configuration
+func IsRemovedMember(mongoDB *db.MongoDB, host string) bool {
+	podName := GetMemberPodName(bson.M{"host": host})
+	for _, member := range statefulset.Members {
+		prefix := statefulset.GetName(mongoDB, member) + "-"
+		if !strings.HasPrefix(podName, prefix) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(podName, prefix))
+		if err != nil {
+			continue
+		}
+		return index >= getRequestedReplicas(mongoDB, member)
+	}
+	return false
+}
+
+// getRequestedReplicas return the replicas of the kind of member in the spec, whatever the hibernation
+func getRequestedReplicas(mongoDB *db.MongoDB, member string) int {
+	var replicas *int32
+	switch member {
+	case statefulset.DataMember:
+		replicas = mongoDB.Spec.Replicas
+	case statefulset.ArbiterMember:
+		if mongoDB.Spec.Arbiter != nil {
+			replicas = mongoDB.Spec.Arbiter.Replicas
+		}
+	case statefulset.HiddenMember:
+		if mongoDB.Spec.Hidden != nil {
+			replicas = mongoDB.Spec.Hidden.Replicas
+		}
+	}
+	if replicas == nil {
+		return 0
+	}
+	return int(*replicas)
+}
+
+func getSortedHosts(hosts map[string]bson.M) []string {
+	var sorted []string
+	for host := range hosts {
+		sorted = append(sorted, host)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func toInt64(value interface{}) int64 {
+	return int64(toFloat64(value))
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// isSameValue compares the setting values, the numbers are compared whatever their bson type
+func isSameValue(current, wanted interface{}) bool {
+	switch w := wanted.(type) {
+	case bson.M:
+		c, _ := current.(bson.M)
+		if len(c) != len(w) {
+			return false
+		}
+		for key, value := range w {
+			if c[key] != value {
+				return false
+			}
+		}
+		return true
+	case int32, int64, float64:
+		switch current.(type) {
+		case int32, int64, float64:
+			return toFloat64(current) == toFloat64(wanted)
+		}
+		return false
+	}
+	return current == wanted
+}
+
+// isPodStarted return whether the startup probe of the mongodb container succeeded
+// isPodReady return whether the mongod container passes its readiness probe
+func isPodReady(po *corev1.Pod) bool {
+	for _, status := range po.Status.ContainerStatuses {
+		if status.Name == statefulset.MongoName {
+			return status.Ready
+		}
+	}
+	return false
+}
+
+func isPodStarted(po *corev1.Pod) bool {
+	for _, status := range po.Status.ContainerStatuses {
+		if status.Name == statefulset.MongoName {
+			return status.Started != nil && *status.Started
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+)
+
+var _ = Describe("ReplicaSet", func() {
+	var mongoDB *db.MongoDB
+	BeforeEach(func() {
+		replicas, one := int32(3), int32(1)
+		mongoDB = &db.MongoDB{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: db.MongoDBSpec{
+				Version:  "4.4",
+				Replicas: &replicas,
+				Arbiter:  &db.ArbiterSpec{Replicas: &one},
+				Hidden:   &db.HiddenSpec{Replicas: &one},
+			},
+		}
+	})
+	Context("data member settings", func() {
+		It("resets the settings missing from the spec to the defaults", func() {
+			Expect(getDataMemberSettings(mongoDB, 0)).To(Equal(bson.M{
+				"priority":   int32(1),
+				"votes":      int32(1),
+				"slaveDelay": int32(0),
+				"tags":       bson.M{},
+			}))
+		})
+		It("applies the settings of the member with the index", func() {
+			zero, delay := int32(0), int32(3600)
+			mongoDB.Spec.Members = []db.MemberSpec{
+				{Index: 2, Priority: &zero, Votes: &zero, SecondaryDelaySecs: &delay, Tags: map[string]string{"dc": "a"}},
+			}
+			Expect(getDataMemberSettings(mongoDB, 1)).To(HaveKeyWithValue("votes", int32(1)))
+			Expect(getDataMemberSettings(mongoDB, 2)).To(Equal(bson.M{
+				"priority":   int32(0),
+				"votes":      int32(0),
+				"slaveDelay": int32(3600),
+				"tags":       bson.M{"dc": "a"},
+			}))
+		})
+		It("uses the delay setting of the version", func() {
+			mongoDB.Spec.Version = "5.0"
+			Expect(getDataMemberSettings(mongoDB, 0)).To(HaveKey("secondaryDelaySecs"))
+			Expect(getDataMemberSettings(mongoDB, 0)).ToNot(HaveKey("slaveDelay"))
+		})
+	})
+	Context("member settings", func() {
+		It("returns the settings of the arbiter and hidden members", func() {
+			Expect(getMemberSettings(statefulset.ArbiterMember)).To(Equal(bson.M{"arbiterOnly": true}))
+			Expect(getMemberSettings(statefulset.HiddenMember)).To(Equal(bson.M{"hidden": true, "priority": int32(0)}))
+		})
+	})
+	Context("managed members", func() {
+		It("handles the arbiter and hidden members", func() {
+			Expect(isManagedMember(mongoDB, "test-arbiter-0")).To(BeTrue())
+			Expect(isManagedMember(mongoDB, "test-hidden-1")).To(BeTrue())
+		})
+		It("handles the data-bearing members beyond the replicas", func() {
+			Expect(isManagedMember(mongoDB, "test-2")).To(BeFalse())
+			Expect(isManagedMember(mongoDB, "test-3")).To(BeTrue())
+			mongoDB.Spec.Flavor = db.FlavorBitnami
+			Expect(isManagedMember(mongoDB, "test-3")).To(BeTrue())
+		})
+		It("keeps the members of the hibernated instance", func() {
+			meta.SetStatusCondition(&mongoDB.Status.Conditions, metav1.Condition{
+				Type: db.ConditionHibernated, Status: metav1.ConditionTrue, Reason: "Hibernated",
+			})
+			Expect(isManagedMember(mongoDB, "test-0")).To(BeFalse())
+		})
+		It("ignores the members of other instances", func() {
+			Expect(isManagedMember(mongoDB, "other-3")).To(BeFalse())
+			Expect(isManagedMember(mongoDB, "test-other-0")).To(BeFalse())
+			Expect(isManagedMember(mongoDB, "10")).To(BeFalse())
+		})
+	})
+	Context("ready members", func() {
+		var ctx context.Context
+		// pod returns the data-bearing member whose mongod started, ready once it holds its replica set state
+		pod := func(index int, ready bool) *corev1.Pod {
+			started := true
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-%d", index),
+					Namespace: "default",
+					Labels:    statefulset.GetLabels(mongoDB, statefulset.DataMember),
+				},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+					{Name: statefulset.MongoName, Started: &started, Ready: ready},
+				}},
+			}
+		}
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+		It("joins the members once the first member initiated the replica set", func() {
+			// the official and Percona images only initiate the replica set on the first member, the
+			// other members are never ready and the instance never Ready until they are added
+			mongoDB.Spec.Flavor = db.FlavorOfficial
+			r := fake.NewClientBuilder().WithObjects(pod(0, true), pod(1, false)).Build()
+			Expect(HasReadyMember(ctx, r, mongoDB)).To(BeTrue())
+		})
+		It("removes the members no longer requested while the instance is not Ready", func() {
+			// a member scaled down is unreachable and holds the instance NotReady until it is removed
+			replicas := int32(1)
+			mongoDB.Spec.Replicas = &replicas
+			r := fake.NewClientBuilder().WithObjects(pod(0, true)).Build()
+			Expect(HasReadyMember(ctx, r, mongoDB)).To(BeTrue())
+			Expect(isManagedMember(mongoDB, "test-1")).To(BeTrue())
+		})
+		It("waits for the replica set to be initiated", func() {
+			r := fake.NewClientBuilder().WithObjects(pod(0, false), pod(1, false)).Build()
+			Expect(HasReadyMember(ctx, r, mongoDB)).To(BeFalse())
+		})
+		It("ignores the pods of the other members and of the hibernated instance", func() {
+			arbiter := pod(0, true)
+			arbiter.Name = "test-arbiter-0"
+			arbiter.Labels = statefulset.GetLabels(mongoDB, statefulset.ArbiterMember)
+			r := fake.NewClientBuilder().WithObjects(arbiter).Build()
+			Expect(HasReadyMember(ctx, r, mongoDB)).To(BeFalse())
+			r = fake.NewClientBuilder().WithObjects(pod(0, true)).Build()
+			meta.SetStatusCondition(&mongoDB.Status.Conditions, metav1.Condition{
+				Type: db.ConditionHibernated, Status: metav1.ConditionTrue, Reason: "Hibernated",
+			})
+			Expect(HasReadyMember(ctx, r, mongoDB)).To(BeFalse())
+		})
+	})
+	Context("removed members", func() {
+		host := func(podName, service string) string {
+			return podName + "." + service + ".default.svc.cluster.local:27017"
+		}
+		It("handles the data-bearing members beyond the replicas", func() {
+			Expect(IsRemovedMember(mongoDB, host("test-2", "test-headless"))).To(BeFalse())
+			Expect(IsRemovedMember(mongoDB, host("test-3", "test-headless"))).To(BeTrue())
+		})
+		It("handles the arbiter and hidden members dropped from the spec", func() {
+			Expect(IsRemovedMember(mongoDB, host("test-arbiter-0", "test-arbiter-headless"))).To(BeFalse())
+			Expect(IsRemovedMember(mongoDB, host("test-hidden-1", "test-hidden-headless"))).To(BeTrue())
+			mongoDB.Spec.Arbiter = nil
+			Expect(IsRemovedMember(mongoDB, host("test-arbiter-0", "test-arbiter-headless"))).To(BeTrue())
+		})
+		It("keeps the members of the hibernated instance and ignores the other hosts", func() {
+			meta.SetStatusCondition(&mongoDB.Status.Conditions, metav1.Condition{
+				Type: db.ConditionHibernated, Status: metav1.ConditionTrue, Reason: "Hibernated",
+			})
+			Expect(IsRemovedMember(mongoDB, host("test-0", "test-headless"))).To(BeFalse())
+			Expect(IsRemovedMember(mongoDB, "10.0.0.1:27017")).To(BeFalse())
+			Expect(IsRemovedMember(mongoDB, host("other-5", "other-headless"))).To(BeFalse())
+		})
+	})
+	Context("restored members", func() {
+		It("replaces the members of the source instance by the first member", func() {
+			config := bson.M{"version": int32(3), "members": bson.A{
+				bson.M{"_id": int32(0), "host": "source-0.source-headless.default.svc.cluster.local:27017"},
+				bson.M{"_id": int32(1), "host": "source-1.source-headless.default.svc.cluster.local:27017"},
+			}}
+			restored, err := setRestoredMembers(mongoDB, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(BeTrue())
+			Expect(config["members"]).To(Equal(bson.A{
+				bson.M{"_id": int32(0), "host": statefulset.GetHost(mongoDB, statefulset.DataMember, 0)},
+			}))
+			Expect(config).To(HaveKeyWithValue("version", int32(3)))
+		})
+		It("keeps the members of the instance", func() {
+			members := bson.A{
+				bson.M{"_id": int32(0), "host": statefulset.GetHost(mongoDB, statefulset.DataMember, 0)},
+				bson.M{"_id": int32(1), "host": statefulset.GetHost(mongoDB, statefulset.DataMember, 1)},
+				bson.M{"_id": int32(2), "host": statefulset.GetHost(mongoDB, statefulset.ArbiterMember, 0), "arbiterOnly": true},
+			}
+			config := bson.M{"members": members}
+			restored, err := setRestoredMembers(mongoDB, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(BeFalse())
+			Expect(config["members"]).To(Equal(members))
+		})
+		It("fails without members", func() {
+			_, err := setRestoredMembers(mongoDB, bson.M{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetMemberStates return the replica set state of the members by pod name
+func GetMemberStates(ctx context.Context, c *mongo.Client) (map[string]string, error) {
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "replSetGetStatus", Value: 1},
+	}, options.RunCmd().SetReadPreference(readpref.PrimaryPreferred()))
+	var response struct {
+		Members []struct {
+			Name     string `bson:"name"`
+			StateStr string `bson:"stateStr"`
+		} `bson:"members"`
+	}
+	if err := res.Decode(&response); err != nil {
+		return nil, err
+	}
+	states := make(map[string]string)
+	for _, member := range response.Members {
+		states[GetMemberPodName(bson.M{"host": member.Name})] = member.StateStr
+	}
+	return states, nil
+}
+
+// GetReplicaSetStatus return the state of the members reported by the replica set.
+// The lag of the secondaries is computed from the optime of the primary
+func GetReplicaSetStatus(ctx context.Context, r client.Client, mongoDB *db.MongoDB) ([]db.MemberStatus, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetReplicaSetStatus")
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return nil, err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	res := c.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "replSetGetStatus", Value: 1},
+	}, options.RunCmd().SetReadPreference(readpref.PrimaryPreferred()))
+	var response struct {
+		Members []struct {
+			Name           string    `bson:"name"`
+			Health         float64   `bson:"health"`
+			StateStr       string    `bson:"stateStr"`
+			OptimeDate     time.Time `bson:"optimeDate"`
+			SyncSourceHost string    `bson:"syncSourceHost"`
+		} `bson:"members"`
+	}
+	if err = res.Decode(&response); err != nil {
+		log.Error(err, "get replica set status failed")
+		return nil, err
+	}
+	var primary time.Time
+	for _, member := range response.Members {
+		if member.StateStr == "PRIMARY" {
+			primary = member.OptimeDate
+		}
+	}
+	var members []db.MemberStatus
+	for _, member := range response.Members {
+		status := db.MemberStatus{
+			Name:       member.Name,
+			State:      member.StateStr,
+			Health:     member.Health == 1,
+			SyncSource: member.SyncSourceHost,
+		}
+		if !member.OptimeDate.IsZero() {
+			optime := metav1.NewTime(member.OptimeDate)
+			status.Optime = &optime
+			if member.StateStr == "SECONDARY" && !primary.IsZero() {
+				lag := int64(primary.Sub(member.OptimeDate) / time.Second)
+				if lag < 0 {
+					lag = 0
+				}
+				status.LagSeconds = &lag
+			}
+		}
+		members = append(members, status)
+	}
+	return members, nil
+}
+
+// SetRoles maintains the role label of the data-bearing member pods from the replica set status.
+// The label is removed from the pods neither primary nor secondary
+func SetRoles(ctx context.Context, r client.Client, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("SetRoles")
+	c, err := GetClient(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get MongoDB client")
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	states, err := GetMemberStates(ctx, c)
+	if err != nil {
+		log.Error(err, "get replica set status failed")
+		return err
+	}
+	pods := &corev1.PodList{}
+	if err = r.List(ctx, pods, client.InNamespace(mongoDB.Namespace),
+		client.MatchingLabels(statefulset.GetLabels(mongoDB, statefulset.DataMember))); err != nil {
+		log.Error(err, "list pods failed")
+		return err
+	}
+	for i := range pods.Items {
+		po := &pods.Items[i]
+		var role string
+		switch states[po.Name] {
+		case "PRIMARY":
+			role = statefulset.RolePrimary
+		case "SECONDARY":
+			role = statefulset.RoleSecondary
+		}
+		if po.Labels[statefulset.RoleLabel] == role {
+			continue
+		}
+		log.V(1).Info("set pod role", "pod", po.Name, "role", role)
+		patch := client.MergeFrom(po.DeepCopy())
+		if role == "" {
+			delete(po.Labels, statefulset.RoleLabel)
+		} else {
+			if po.Labels == nil {
+				po.Labels = make(map[string]string)
+			}
+			po.Labels[statefulset.RoleLabel] = role
+		}
+		if err = r.Patch(ctx, po, patch); err != nil {
+			log.Error(err, "patch pod role failed", "pod", po.Name)
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package util_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/w6d-io/mongodb/internal/util"
+)
+
+var _ = Describe("Cron", func() {
+	// Wednesday
+	now := time.Date(2026, 10, 21, 12, 30, 0, 0, time.UTC)
+	Context("parsing", func() {
+		It("accepts lists, ranges and steps", func() {
+			_, err := util.ParseCron("*/15 0-6,22 1 * 1-5")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("rejects invalid expressions", func() {
+			for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+				_, err := util.ParseCron(expr)
+				Expect(err).To(HaveOccurred(), expr)
+			}
+		})
+	})
+	Context("activation times", func() {
+		It("returns the previous activation", func() {
+			s, err := util.ParseCron("0 20 * * 1-5")
+			Expect(err).ToNot(HaveOccurred())
+			prev, ok := s.Prev(now)
+			Expect(ok).To(BeTrue())
+			Expect(prev).To(Equal(time.Date(2026, 10, 20, 20, 0, 0, 0, time.UTC)))
+		})
+		It("returns the next activation", func() {
+			s, err := util.ParseCron("0 7 * * 1-5")
+			Expect(err).ToNot(HaveOccurred())
+			next, ok := s.Next(time.Date(2026, 10, 23, 8, 0, 0, 0, time.UTC))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2026, 10, 26, 7, 0, 0, 0, time.UTC)))
+		})
+		It("matches the current minute as previous activation", func() {
+			s, err := util.ParseCron("30 12 * * *")
+			Expect(err).ToNot(HaveOccurred())
+			prev, ok := s.Prev(now.Add(20 * time.Second))
+			Expect(ok).To(BeTrue())
+			Expect(prev).To(Equal(now))
+		})
+		It("matches either day field when both are restricted", func() {
+			s, err := util.ParseCron("0 0 1 * 0")
+			Expect(err).ToNot(HaveOccurred())
+			next, ok := s.Next(now)
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+})
@@ -18,9 +18,12 @@ package util
 
 import (
 	"context"
+	cryptorand "crypto/rand"
 	"crypto/sha256"
 	"fmt"
+	"math/big"
 	"math/rand"
+	"strconv"
 	"strings"
 
 	"k8s.io/client-go/tools/cache"
@@ -86,6 +89,7 @@ func GetServiceAccount(podTemplate *k8sv1alpha1.PodTemplate) string {
 	}
 	return sa
 }
+
 func GetSecurityContext(podTemplate *k8sv1alpha1.PodTemplate) *corev1.PodSecurityContext {
 	sc := config.GetSecurityContext()
 	if podTemplate != nil && podTemplate.SecurityContext != nil {
@@ -94,6 +98,15 @@ func GetSecurityContext(podTemplate *k8sv1alpha1.PodTemplate) *corev1.PodSecurit
 	return sc
 }
 
+// GetContainerSecurityContext return a copy of the container security context, each container gets its own
+func GetContainerSecurityContext(podTemplate *k8sv1alpha1.PodTemplate) *corev1.SecurityContext {
+	sc := config.GetContainerSecurityContext()
+	if podTemplate != nil && podTemplate.ContainerSecurityContext != nil {
+		sc = podTemplate.ContainerSecurityContext.DeepCopy()
+	}
+	return sc
+}
+
 func GetAffinity(podTemplate *k8sv1alpha1.PodTemplate) *corev1.Affinity {
 	af := config.GetAffinity()
 	if podTemplate != nil && podTemplate.Affinity != nil {
@@ -117,6 +130,14 @@ func LabelsForMongoDB(name string) map[string]string {
 	}
 }
 
+// LabelsForMember return the labels of the arbiter or hidden members
+func LabelsForMember(name, member string) map[string]string {
+	return map[string]string{
+		"db.w6d.io/component": "mongodb-" + member,
+		"db.w6d.io/release":   name,
+	}
+}
+
 func GetTypesNamespaceNamed(ctx context.Context, object runtime.Object) types.NamespacedName {
 	o, err := meta.Accessor(object)
 	if err != nil {
@@ -177,6 +198,32 @@ func GeneratePassword(passwordLength, minSpecialChar, minNum, minUpperCase int)
 	return string(inRune)
 }
 
+// GenerateURLSafePassword return a password with letters and digits only so that
+// it can be used as is in a connection string. The characters are drawn from crypto/rand
+func GenerateURLSafePassword(passwordLength int) string {
+	charSet := lowerCharSet + upperCharSet + numberSet
+	max := big.NewInt(int64(len(charSet)))
+	password := make([]byte, passwordLength)
+	for i := range password {
+		n, err := cryptorand.Int(cryptorand.Reader, max)
+		if err != nil {
+			// the random source of the system is not expected to fail
+			panic(err)
+		}
+		password[i] = charSet[n.Int64()]
+	}
+	return string(password)
+}
+
+// GetMajorVersion return the major number of the version, 0 when it cannot be parsed
+func GetMajorVersion(version string) int {
+	major, err := strconv.Atoi(strings.Split(version, ".")[0])
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
 // StringInArray ...
 func StringInArray(needle string, haystack []string) bool {
 	for _, elem := range haystack {
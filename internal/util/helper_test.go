@@ -0,0 +1,66 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package util_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/w6d-io/mongodb/internal/util"
+	corev1 "k8s.io/api/core/v1"
+
+	k8sv1alpha1 "github.com/w6d-io/mongodb/apis/k8s/v1alpha1"
+)
+
+var _ = Describe("Helper", func() {
+	Context("password generation", func() {
+		It("generates a url safe password", func() {
+			password := util.GenerateURLSafePassword(30)
+			Expect(password).To(HaveLen(30))
+			Expect(password).To(MatchRegexp("^[a-zA-Z0-9]+$"))
+		})
+		It("generates a password with the requested length", func() {
+			Expect(util.GeneratePassword(30, 3, 3, 2)).To(HaveLen(30))
+		})
+	})
+	Context("version", func() {
+		It("returns the major version", func() {
+			Expect(util.GetMajorVersion("4.4")).To(Equal(4))
+			Expect(util.GetMajorVersion("5.0.3")).To(Equal(5))
+			Expect(util.GetMajorVersion("latest")).To(Equal(0))
+		})
+	})
+	Context("labels", func() {
+		It("does not select the member pods with the instance labels", func() {
+			member := util.LabelsForMember("test", "arbiter")
+			Expect(member).To(HaveKeyWithValue("db.w6d.io/release", "test"))
+			Expect(member).ToNot(HaveKeyWithValue("db.w6d.io/component", "mongodb"))
+		})
+	})
+	Context("security context", func() {
+		It("returns a copy of the container security context of the pod template", func() {
+			nonRoot := true
+			podTemplate := &k8sv1alpha1.PodTemplate{
+				ContainerSecurityContext: &corev1.SecurityContext{RunAsNonRoot: &nonRoot},
+			}
+			sc := util.GetContainerSecurityContext(podTemplate)
+			Expect(sc).To(Equal(podTemplate.ContainerSecurityContext))
+			Expect(sc).ToNot(BeIdenticalTo(podTemplate.ContainerSecurityContext))
+			*sc.RunAsNonRoot = false
+			Expect(*podTemplate.ContainerSecurityContext.RunAsNonRoot).To(BeTrue())
+		})
+	})
+})
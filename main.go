@@ -30,12 +30,16 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	dbv1alpha1 "github.com/w6d-io/mongodb/api/v1alpha1"
 	"github.com/w6d-io/mongodb/controllers"
@@ -62,6 +66,13 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+const (
+	defaultMetricsAddr      = ":8080"
+	defaultProbeAddr        = ":8081"
+	defaultWebhookPort      = 9443
+	defaultLeaderElectionID = "644757cd.w6d.io"
+)
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -73,11 +84,20 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
-	flag.StringVar(&metricsAddr, "metrics-bind-address", util.LookupEnvOrString("METRICS_ADDRESS", ":8080"), "The address the metric endpoint binds to.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", util.LookupEnvOrString("PROBE_ADDRESS", ":8081"), "The address the probe endpoint binds to.")
+	var watchNamespaces string
+	var leaderElectionID string
+	flag.StringVar(&metricsAddr, "metrics-bind-address", util.LookupEnvOrString("METRICS_ADDRESS", ""),
+		"The address the metric endpoint binds to, the one of the config file or "+defaultMetricsAddr+" when empty.")
+	flag.StringVar(&probeAddr, "health-probe-bind-address", util.LookupEnvOrString("PROBE_ADDRESS", ""),
+		"The address the probe endpoint binds to, the one of the config file or "+defaultProbeAddr+" when empty.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", util.LookupEnvOrBool("ENABLE_LEADER", false),
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", util.LookupEnvOrString("WATCH_NAMESPACES", ""),
+		"Comma separated namespaces the operator is restricted to, the ones of the config file or every namespace when empty.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", util.LookupEnvOrString("LEADER_ELECTION_ID", ""),
+		"Name of the leader election lock, distinct for the operators installed side by side. "+
+			"The one of the config file or "+defaultLeaderElectionID+" when empty.")
 	opts := zap.Options{
 		Development:     os.Getenv("RELEASE") != "prod",
 		StacktraceLevel: zapcore.PanicLevel,
@@ -90,33 +110,68 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts), zap.RawZapOpts(zapraw.AddCaller(), zapraw.AddCallerSkip(-1))))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	options, err := config.GetManagerOptions(ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "644757cd.w6d.io",
+		LeaderElectionID:       leaderElectionID,
 	})
+	if err != nil {
+		setupLog.Error(err, "unable to load the manager options from the config file")
+		os.Exit(1)
+	}
+	if options.MetricsBindAddress == "" {
+		options.MetricsBindAddress = defaultMetricsAddr
+	}
+	if options.HealthProbeBindAddress == "" {
+		options.HealthProbeBindAddress = defaultProbeAddr
+	}
+	if options.Port == 0 {
+		options.Port = defaultWebhookPort
+	}
+	if options.LeaderElectionID == "" {
+		options.LeaderElectionID = defaultLeaderElectionID
+	}
+	if namespaces := config.GetWatchNamespaces(watchNamespaces); len(namespaces) != 0 {
+		setupLog.Info("restrict the operator to namespaces", "namespaces", namespaces)
+		options.Namespace = namespaces[0]
+		if len(namespaces) > 1 {
+			options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		}
+		// the cluster-scoped resources cannot be cached by a namespaced cache
+		options.ClientBuilder = manager.NewClientBuilder().WithUncached(&corev1.Node{}, &storagev1.StorageClass{})
+	}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.MongoDBReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MongoDB"),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("MongoDB"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("mongodb-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MongoDB")
 		os.Exit(1)
 	}
 	if err = (&controllers.MongoDBUserReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("MongoDBUser"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("mongodbuser-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MongoDBUser")
+		os.Exit(1)
+	}
+	if err = (&controllers.MongoDBBackupReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MongoDBUser"),
+		Log:    ctrl.Log.WithName("controllers").WithName("MongoDBBackup"),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MongoDBUser")
+		setupLog.Error(err, "unable to create controller", "controller", "MongoDBBackup")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
@@ -129,6 +184,13 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "MongoDBUser")
 			os.Exit(1)
 		}
+	} else {
+		setupLog.Info("webhooks disabled, the resources are not validated on admission")
+	}
+
+	if err := mgr.Add(config.Watcher{}); err != nil {
+		setupLog.Error(err, "unable to watch the config file")
+		os.Exit(1)
 	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
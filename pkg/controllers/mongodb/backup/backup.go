@@ -0,0 +1,180 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/w6d-io/mongodb/internal/mongodb"
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/snapshot"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"go.mongodb.org/mongo-driver/bson"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CutTimeout is the maximum time the writes of the member are locked waiting for the snapshot to be cut
+const CutTimeout = 2 * time.Minute
+
+// GetMongoDB return the instance referenced by the backup
+func GetMongoDB(ctx context.Context, r client.Client, backup *db.MongoDBBackup) (*db.MongoDB, error) {
+	log := util.GetLog(ctx, backup).WithName("Backup").WithName("GetMongoDB")
+	if backup.Spec.DBRef == nil {
+		return nil, errors.New("dbref is not set")
+	}
+	mongoDB := &db.MongoDB{}
+	if err := r.Get(ctx, types.NamespacedName{Name: backup.Spec.DBRef.Name, Namespace: backup.Namespace}, mongoDB); err != nil {
+		log.Error(err, "get MongoDB failed")
+		return nil, err
+	}
+	return mongoDB, nil
+}
+
+// CreateSecret copies the credentials of the instance into a secret owned by the backup.
+// The restored data holds the users of the instance, the secret is the authSecret of the restored instances
+func CreateSecret(ctx context.Context, r client.Client, scheme *runtime.Scheme, backup *db.MongoDBBackup, mongoDB *db.MongoDB) (string, error) {
+	log := util.GetLog(ctx, backup).WithName("Backup").WithName("CreateSecret")
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mongoDB.Name, Namespace: mongoDB.Namespace}, source); err != nil {
+		log.Error(err, "get instance secret failed")
+		return "", err
+	}
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backup.Name,
+			Namespace: backup.Namespace,
+		},
+		Data: source.Data,
+	}
+	if err := ctrl.SetControllerReference(backup, sec, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return "", err
+	}
+	log.V(1).Info("create backup secret")
+	if err := r.Create(ctx, sec); err != nil && !apierrors.IsAlreadyExists(err) {
+		log.Error(err, "create backup secret failed")
+		return "", err
+	}
+	return sec.Name, nil
+}
+
+// SetMember selects the member of the instance the backup is taken from. Unless the backup relies on the journal,
+// the lock of its writes is recorded before it is taken so that any later reconcile can release it
+func SetMember(ctx context.Context, r client.Client, backup *db.MongoDBBackup, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, backup).WithName("Backup").WithName("SetMember")
+	host, err := mongodb.GetBackupMember(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "get backup member failed")
+		return err
+	}
+	backup.Status.Member = mongodb.GetMemberPodName(bson.M{"host": host})
+	backup.Status.Snapshot = backup.Name
+	backup.Status.FsyncLock = nil
+	if backup.Spec.IsFsyncLock() {
+		backup.Status.FsyncLock = &db.FsyncLockStatus{Host: host, Time: metav1.Now()}
+	}
+	return nil
+}
+
+// Snapshot takes the snapshot of the data volume of the member of the backup. The writes of the member are
+// locked when recorded in the status, they are released by Unlock once the snapshot is cut
+func Snapshot(ctx context.Context, r client.Client, scheme *runtime.Scheme, backup *db.MongoDBBackup, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, backup).WithName("Backup").WithName("Snapshot")
+	labels := util.LabelsForMongoDB(mongoDB.Name)
+	labels[snapshot.SnapshotLabel] = snapshot.BackupSnapshot
+	vs := snapshot.GetVolumeSnapshot(backup.Namespace, backup.Status.Snapshot,
+		fmt.Sprintf("%s-%s", statefulset.DataVolumeName, backup.Status.Member), backup.Spec.VolumeSnapshotClassName, labels)
+	if err := ctrl.SetControllerReference(backup, vs, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return err
+	}
+	if backup.Status.FsyncLock == nil {
+		log.V(1).Info("snapshot relying on the journal", "member", backup.Status.Member)
+		return snapshot.Create(ctx, r, vs)
+	}
+	c, err := mongodb.GetMemberClient(ctx, r, mongoDB, backup.Status.FsyncLock.Host)
+	if err != nil {
+		log.Error(err, "get MongoDB member client")
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	log.V(1).Info("lock member writes", "member", backup.Status.Member)
+	if err = mongodb.FsyncLock(ctx, c); err != nil {
+		log.Error(err, "fsync lock failed")
+		return err
+	}
+	return snapshot.Create(ctx, r, vs)
+}
+
+// IsCut return whether the snapshot is cut so that the writes of the member can be unlocked. It fails when
+// the snapshot failed or was not cut within CutTimeout from the lock
+func IsCut(backup *db.MongoDBBackup, status *snapshot.Status, now time.Time) (bool, error) {
+	switch {
+	case status.Error != "":
+		return false, errors.New(status.Error)
+	case status.CreationTime != "":
+		return true, nil
+	case backup.Status.FsyncLock != nil && now.Sub(backup.Status.FsyncLock.Time.Time) > CutTimeout:
+		return false, fmt.Errorf("snapshot not cut within %s", CutTimeout)
+	}
+	return false, nil
+}
+
+// Unlock releases the writes of the member recorded in the status of the backup and clears it.
+// The lock is gone along with the instance
+func Unlock(ctx context.Context, r client.Client, backup *db.MongoDBBackup) error {
+	log := util.GetLog(ctx, backup).WithName("Backup").WithName("Unlock")
+	if backup.Status.FsyncLock == nil {
+		return nil
+	}
+	mongoDB, err := GetMongoDB(ctx, r, backup)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			backup.Status.FsyncLock = nil
+			return nil
+		}
+		return err
+	}
+	c, err := mongodb.GetMemberClient(ctx, r, mongoDB, backup.Status.FsyncLock.Host)
+	if err != nil {
+		log.Error(err, "get MongoDB member client")
+		return err
+	}
+	defer func() {
+		_ = c.Disconnect(ctx)
+	}()
+	log.V(1).Info("unlock member writes", "member", backup.Status.Member)
+	if err = mongodb.FsyncUnlock(ctx, c); err != nil {
+		log.Error(err, "fsync unlock failed")
+		return err
+	}
+	backup.Status.FsyncLock = nil
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package backup
+
+import (
+	"time"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/pkg/k8s/snapshot"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backup", func() {
+	Context("snapshot cut", func() {
+		var (
+			now    time.Time
+			backup *db.MongoDBBackup
+		)
+		BeforeEach(func() {
+			now = time.Now()
+			backup = &db.MongoDBBackup{
+				Status: db.MongoDBBackupStatus{
+					FsyncLock: &db.FsyncLockStatus{
+						Host: "mongodb-0.mongodb-headless.default.svc.cluster.local:27017",
+						Time: metav1.NewTime(now.Add(-10 * time.Second)),
+					},
+				},
+			}
+		})
+		It("waits while the snapshot is not cut", func() {
+			cut, err := IsCut(backup, &snapshot.Status{}, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cut).To(BeFalse())
+		})
+		It("is cut once the creation time is set", func() {
+			cut, err := IsCut(backup, &snapshot.Status{CreationTime: "2026-10-19T10:00:00Z"}, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cut).To(BeTrue())
+		})
+		It("fails when the snapshot failed", func() {
+			cut, err := IsCut(backup, &snapshot.Status{Error: "volume not found"}, now)
+			Expect(err).To(MatchError("volume not found"))
+			Expect(cut).To(BeFalse())
+		})
+		It("fails when the snapshot is not cut in time", func() {
+			cut, err := IsCut(backup, &snapshot.Status{}, now.Add(CutTimeout))
+			Expect(err).To(HaveOccurred())
+			Expect(cut).To(BeFalse())
+		})
+		It("is cut even late", func() {
+			cut, err := IsCut(backup, &snapshot.Status{CreationTime: "2026-10-19T10:00:00Z"}, now.Add(CutTimeout))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cut).To(BeTrue())
+		})
+	})
+})
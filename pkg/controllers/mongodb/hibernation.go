@@ -0,0 +1,62 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"time"
+
+	"github.com/w6d-io/mongodb/internal/util"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+// IsHibernated return whether the time is within a sleep window of the instance, and the next time
+// a window starts or ends. The instance sleeps when a sleep time is more recent than the following wake time
+func IsHibernated(mongoDB *db.MongoDB, now time.Time) (bool, time.Time, error) {
+	var next time.Time
+	hibernation := mongoDB.Spec.Hibernation
+	if hibernation == nil {
+		return false, next, nil
+	}
+	location, err := time.LoadLocation(hibernation.TimeZone)
+	if err != nil {
+		return false, next, err
+	}
+	now = now.In(location)
+	hibernated := false
+	for _, schedule := range hibernation.Schedules {
+		sleep, err := util.ParseCron(schedule.Sleep)
+		if err != nil {
+			return false, next, err
+		}
+		wake, err := util.ParseCron(schedule.Wake)
+		if err != nil {
+			return false, next, err
+		}
+		lastSleep, slept := sleep.Prev(now)
+		lastWake, woke := wake.Prev(now)
+		if slept && (!woke || lastSleep.After(lastWake)) {
+			hibernated = true
+		}
+		for _, s := range []*util.CronSchedule{sleep, wake} {
+			if t, ok := s.Next(now); ok && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return hibernated, next, nil
+}
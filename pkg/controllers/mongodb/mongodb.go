@@ -20,6 +20,9 @@ import (
 	"context"
 
 	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/configmap"
+	"github.com/w6d-io/mongodb/pkg/k8s/monitoring"
+	"github.com/w6d-io/mongodb/pkg/k8s/pdb"
 	"github.com/w6d-io/mongodb/pkg/k8s/secret"
 	"github.com/w6d-io/mongodb/pkg/k8s/service"
 	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
@@ -32,11 +35,11 @@ import (
 func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
 	var err error
 	log := util.GetLog(ctx, mongoDB)
-	//err = configmap.CreateUpdate(ctx, r, scheme, mongoDB)
-	//if err != nil {
-	//	log.Error(err, "configmap processing failed")
-	//	return err
-	//}
+	err = configmap.CreateUpdate(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "configmap processing failed")
+		return err
+	}
 	//record.EventRecorder(mongoDB, corev1.EventTypeNormal, "Creating", "creating secret in progress")
 	err = secret.Create(ctx, r, scheme, mongoDB)
 	if err != nil {
@@ -48,10 +51,40 @@ func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme,
 		log.Error(err, "statefulSet processing failed")
 		return err
 	}
+	err = pdb.CreateUpdate(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "pod disruption budget processing failed")
+		return err
+	}
 	err = service.Create(ctx, r, scheme, mongoDB)
 	if err != nil {
 		log.Error(err, "service processing failed")
 		return err
 	}
+	err = service.CreateDeleteHeadless(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "headless services processing failed")
+		return err
+	}
+	err = service.CreateRoles(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "role services processing failed")
+		return err
+	}
+	err = service.CreateUpdateExternal(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "external services processing failed")
+		return err
+	}
+	err = service.CreateDeleteMetrics(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "metrics service processing failed")
+		return err
+	}
+	err = monitoring.CreateUpdate(ctx, r, scheme, mongoDB)
+	if err != nil {
+		log.Error(err, "monitoring processing failed")
+		return err
+	}
 	return nil
 }
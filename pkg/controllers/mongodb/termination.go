@@ -0,0 +1,110 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package mongodb
+
+import (
+	"context"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/controllers/mongodb/user"
+	"github.com/w6d-io/mongodb/pkg/k8s/secret"
+	"github.com/w6d-io/mongodb/pkg/k8s/snapshot"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+// Terminate enforces the termination policy of the deleted instance. The users referencing the
+// instance are deleted first so that they are dropped while the database is running.
+// It returns false while the users or the final snapshots are pending or when the policy prevents the deletion
+func Terminate(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (bool, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("Terminate")
+	policy := mongoDB.Spec.TerminationPolicy
+	if policy == "" {
+		policy = db.TerminationPolicyHalt
+	}
+	log = log.WithValues("policy", policy)
+	if policy == db.TerminationPolicyDoNotTerminate {
+		log.Info("deletion prevented by the termination policy")
+		return false, nil
+	}
+	users, err := user.ListByMongoDB(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "list users failed")
+		return false, err
+	}
+	for i := range users {
+		if users[i].DeletionTimestamp != nil {
+			continue
+		}
+		log.V(1).Info("delete user", "user", users[i].Name)
+		if err = r.Delete(ctx, &users[i]); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "delete user failed", "user", users[i].Name)
+			return false, err
+		}
+	}
+	if len(users) != 0 {
+		log.V(1).Info("waiting for the users to be deleted", "count", len(users))
+		return false, nil
+	}
+	claims, err := statefulset.ListClaims(ctx, r, mongoDB)
+	if err != nil {
+		log.Error(err, "list claims failed")
+		return false, err
+	}
+	if mongoDB.Spec.FinalSnapshot != nil {
+		ready := true
+		labels := util.LabelsForMongoDB(mongoDB.Name)
+		labels[snapshot.SnapshotLabel] = snapshot.FinalSnapshot
+		for _, claim := range claims {
+			name := claim.Name + "-" + snapshot.FinalSnapshot
+			vs := snapshot.GetVolumeSnapshot(mongoDB.Namespace, name, claim.Name, mongoDB.Spec.FinalSnapshot.VolumeSnapshotClassName, labels)
+			if err = snapshot.Create(ctx, r, vs); err != nil {
+				log.Error(err, "create final snapshot failed", "pvc", claim.Name)
+				return false, err
+			}
+			ok, err := snapshot.IsReady(ctx, r, mongoDB.Namespace, name)
+			if err != nil {
+				log.Error(err, "get final snapshot failed", "snapshot", name)
+				return false, err
+			}
+			ready = ready && ok
+		}
+		if !ready {
+			log.V(1).Info("waiting for the final snapshots")
+			return false, nil
+		}
+	}
+	if policy == db.TerminationPolicyDelete || policy == db.TerminationPolicyWipeOut {
+		for i := range claims {
+			log.V(1).Info("delete claim", "pvc", claims[i].Name)
+			if err = r.Delete(ctx, &claims[i]); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "delete claim failed", "pvc", claims[i].Name)
+				return false, err
+			}
+		}
+	}
+	if policy != db.TerminationPolicyWipeOut {
+		if err = secret.Orphan(ctx, r, mongoDB); err != nil {
+			log.Error(err, "orphan secret failed")
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/config"
+	"github.com/w6d-io/mongodb/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Adopt takes over the user existing in the database according to the adoption policy of the resource.
+// Only the parentID of the custom data is rewritten, the other fields are kept, the roles and the password are set by the update that follows.
+// It returns nil when the user does not exist or is already handled by the resource
+func Adopt(ctx context.Context, r client.Client, c *mongo.Client, user *db.MongoDBUser) (*db.Adoption, error) {
+	log := util.GetLog(ctx, user).WithName("User").WithName("Adopt")
+	log.V(1).Info("adopt MongoDB user")
+	users, err := GetUser(ctx, c, user)
+	if err != nil {
+		log.Error(err, "get user failed")
+		return nil, err
+	}
+	if len(users) == 0 || isOwner(users[0], user) {
+		return nil, nil
+	}
+	owner := users[0].CustomData.ParentID
+	policy := user.Spec.GetAdoptionPolicy()
+	if !config.IsAdoptionPolicyAllowed(string(policy)) {
+		log.Error(nil, "adoption policy not allowed", "policy", policy)
+		return nil, fmt.Errorf("adoption policy %s is not allowed by the operator configuration", policy)
+	}
+	switch policy {
+	case db.AdoptionPolicyAdopt:
+	case db.AdoptionPolicyAdoptIfNoOwner:
+		exist, err := isOwnerExist(ctx, r, owner)
+		if err != nil {
+			log.Error(err, "check owner exist failed")
+			return nil, err
+		}
+		if exist {
+			log.Error(nil, "this user is already handle by an other resource", "owner", owner)
+			return nil, fmt.Errorf("user %s is handled by the resource %s", user.Spec.Username, owner)
+		}
+	default:
+		log.Error(nil, "this user is already handle by an other resource", "user", user.Spec.Username)
+		return nil, errors.New("a user can be handle only by one resource")
+	}
+	previous, err := bson.MarshalExtJSON(users[0].CustomData, false, false)
+	if err != nil {
+		log.Error(err, "marshal custom data failed")
+		return nil, err
+	}
+	d := c.Database("admin")
+	res := d.RunCommand(ctx, bson.D{
+		{Key: "updateUser", Value: user.Spec.Username},
+		{Key: "customData", Value: GetCustomData(users[0], user)},
+	})
+	if res.Err() != nil {
+		log.Error(res.Err(), "adopt user failed")
+		return nil, res.Err()
+	}
+	log.Info("user adopted", "user", user.Spec.Username, "previousOwner", owner)
+	return &db.Adoption{PreviousOwner: owner, PreviousCustomData: string(previous), Time: metav1.Now()}, nil
+}
+
+// isOwnerExist return whether the resource with the uid still exists. The users of every namespace are looked
+// up since the resources of several namespaces may reference the same instance through an external reference
+func isOwnerExist(ctx context.Context, r client.Client, uid string) (bool, error) {
+	if uid == "" {
+		return false, nil
+	}
+	list := &db.MongoDBUserList{}
+	if err := r.List(ctx, list); err != nil {
+		return false, err
+	}
+	for _, usr := range list.Items {
+		if string(usr.UID) == uid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
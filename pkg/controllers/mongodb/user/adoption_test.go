@@ -0,0 +1,52 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+var _ = Describe("Adoption", func() {
+	var r client.Client
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(db.AddToScheme(scheme)).To(Succeed())
+		r = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&db.MongoDBUser{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a", UID: "uid-a"}},
+			&db.MongoDBUser{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-b", UID: "uid-b"}},
+		).Build()
+	})
+	It("finds the owner in any namespace", func() {
+		Expect(isOwnerExist(context.Background(), r, "uid-a")).To(BeTrue())
+		Expect(isOwnerExist(context.Background(), r, "uid-b")).To(BeTrue())
+	})
+	It("reports the owner deleted", func() {
+		Expect(isOwnerExist(context.Background(), r, "uid-c")).To(BeFalse())
+	})
+	It("reports no owner without uid", func() {
+		Expect(isOwnerExist(context.Background(), r, "")).To(BeFalse())
+	})
+})
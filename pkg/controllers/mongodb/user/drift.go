@@ -0,0 +1,117 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+	"strings"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/mongodb"
+	"github.com/w6d-io/mongodb/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetDrift compares the roles, the custom data and the password of the user in the database with the spec.
+// The user missing or handled by another resource is only stale. The password is
+// checked against the stored credentials so that the authentication restrictions of the user do not apply
+func GetDrift(ctx context.Context, r client.Client, c *mongo.Client, user *db.MongoDBUser) (Drift, error) {
+	log := util.GetLog(ctx, user).WithName("User").WithName("GetDrift")
+	log.V(1).Info("check MongoDB user drift")
+	users, err := GetUser(ctx, c, user)
+	if err != nil {
+		log.Error(err, "get user failed")
+		return Drift{}, err
+	}
+	if len(users) == 0 || !isOwner(users[0], user) {
+		return Drift{Stale: true}, nil
+	}
+	restrictions, err := GetAuthenticationRestrictions(ctx, r, user)
+	if err != nil {
+		log.Error(err, "get authentication restrictions failed")
+		return Drift{}, err
+	}
+	drift := Drift{
+		Roles:      !isSameRoles(users[0].Roles, GetPrivileges(ctx, user)),
+		CustomData: users[0].CustomData.ParentID != string(user.GetUID()),
+		Stale:      !isSameRestrictions(users[0].AuthenticationRestrictions, restrictions),
+	}
+	same, err := mongodb.IsPassword(ctx, c, user.Spec.Username, GetUserPassword(ctx, r, user))
+	if err != nil {
+		log.Error(err, "check user password failed")
+		return Drift{}, err
+	}
+	drift.Password = !same
+	return drift, nil
+}
+
+// IsZero return whether the user matches its spec
+func (d Drift) IsZero() bool {
+	return !d.Roles && !d.CustomData && !d.Password
+}
+
+// String return the comma separated list of what drifted
+func (d Drift) String() string {
+	var fields []string
+	if d.Roles {
+		fields = append(fields, "roles")
+	}
+	if d.CustomData {
+		fields = append(fields, "customData")
+	}
+	if d.Password {
+		fields = append(fields, "password")
+	}
+	return strings.Join(fields, ", ")
+}
+
+// isOwner return whether the user of the database is handled by the resource. A user created by the
+// resource whose custom data has been emptied by hand is still handled by it
+func isOwner(usr User, user *db.MongoDBUser) bool {
+	if usr.CustomData.ParentID == "" {
+		return user.Status.Status == db.MongoDBUSerCreated
+	}
+	return usr.CustomData.ParentID == string(user.GetUID())
+}
+
+// isSameRoles return whether the roles granted in the database are the privileges of the spec
+func isSameRoles(roles []Role, privileges []bson.M) bool {
+	wanted := make(map[Role]bool)
+	for _, priv := range privileges {
+		wanted[Role{Role: toString(priv["role"]), DB: toString(priv["db"])}] = true
+	}
+	granted := make(map[Role]bool)
+	for _, role := range roles {
+		if !wanted[role] {
+			return false
+		}
+		granted[role] = true
+	}
+	return len(granted) == len(wanted)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case db.Permission:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
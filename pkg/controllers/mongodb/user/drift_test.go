@@ -0,0 +1,85 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+var _ = Describe("Drift", func() {
+	var user *db.MongoDBUser
+	BeforeEach(func() {
+		user = &db.MongoDBUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "uid"},
+			Spec: db.MongoDBUserSpec{
+				Username: "test",
+				Privileges: []db.Privilege{
+					{DatabaseName: "app", Permission: "readWrite"},
+					{DatabaseName: "admin", Permission: "read"},
+				},
+			},
+		}
+	})
+	Context("roles", func() {
+		It("matches the roles granted in any order", func() {
+			roles := []Role{{Role: "read", DB: "admin"}, {Role: "readWrite", DB: "app"}}
+			Expect(isSameRoles(roles, GetPrivileges(context.Background(), user))).To(BeTrue())
+		})
+		It("reports the roles revoked or granted by hand", func() {
+			privileges := GetPrivileges(context.Background(), user)
+			Expect(isSameRoles([]Role{{Role: "readWrite", DB: "app"}}, privileges)).To(BeFalse())
+			Expect(isSameRoles([]Role{
+				{Role: "read", DB: "admin"}, {Role: "readWrite", DB: "app"}, {Role: "root", DB: "admin"},
+			}, privileges)).To(BeFalse())
+		})
+	})
+	Context("custom data", func() {
+		It("keeps the other fields and sets the parentID", func() {
+			usr := User{CustomData: CustomData{ParentID: "other", Others: bson.M{"team": "a"}}}
+			Expect(GetCustomData(usr, user)).To(Equal(bson.M{"team": "a", "parentID": "uid"}))
+		})
+		It("records the custom data of a user created by hand without parentID", func() {
+			previous, err := bson.MarshalExtJSON(CustomData{Others: bson.M{"team": "a"}}, false, false)
+			Expect(err).To(Succeed())
+			Expect(string(previous)).To(Equal(`{"team":"a"}`))
+		})
+	})
+	Context("owner", func() {
+		It("handles the user whose parentID is the uid of the resource", func() {
+			Expect(isOwner(User{CustomData: CustomData{ParentID: "uid"}}, user)).To(BeTrue())
+			Expect(isOwner(User{CustomData: CustomData{ParentID: "other"}}, user)).To(BeFalse())
+		})
+		It("handles the user created by the resource whose custom data has been emptied", func() {
+			Expect(isOwner(User{}, user)).To(BeFalse())
+			user.Status.Status = db.MongoDBUSerCreated
+			Expect(isOwner(User{}, user)).To(BeTrue())
+		})
+	})
+	Context("description", func() {
+		It("lists what drifted", func() {
+			Expect(Drift{}.IsZero()).To(BeTrue())
+			Expect(Drift{Roles: true, Password: true}.String()).To(Equal("roles, password"))
+		})
+	})
+})
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/config"
+	"github.com/w6d-io/mongodb/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LoopbackAddress is the client source of a restriction whose addresses of the cluster resolve to none,
+// so that the user cannot authenticate from the cluster until they exist
+const LoopbackAddress = "127.0.0.1"
+
+// GetAuthenticationRestrictions return the authentication restrictions of the user with the client sources
+// of the cluster resolved. The list is empty, not nil, so that the restrictions are removed on update
+func GetAuthenticationRestrictions(ctx context.Context, r client.Client, user *db.MongoDBUser) ([]bson.M, error) {
+	log := util.GetLog(ctx, user).WithName("User").WithName("GetAuthenticationRestrictions")
+	log.V(1).Info("get authentication restrictions")
+	restrictions := make([]bson.M, 0)
+	for _, restriction := range user.Spec.AuthenticationRestrictions {
+		clientSource := append([]string{}, restriction.ClientSource...)
+		var addresses []string
+		var err error
+		switch restriction.ClientSourceFrom {
+		case db.ClientSourceFromPodCIDR:
+			addresses, err = getPodCIDRs(ctx, r)
+		case db.ClientSourceFromNamespacePods:
+			addresses, err = getPodIPs(ctx, r, user.Namespace)
+		}
+		if err != nil {
+			log.Error(err, "get client source failed", "from", restriction.ClientSourceFrom)
+			return nil, err
+		}
+		clientSource = append(clientSource, addresses...)
+		if restriction.ClientSourceFrom != "" && len(clientSource) == 0 {
+			clientSource = append(clientSource, LoopbackAddress)
+		}
+		m := bson.M{}
+		if len(clientSource) != 0 {
+			m["clientSource"] = clientSource
+		}
+		if len(restriction.ServerAddress) != 0 {
+			m["serverAddress"] = restriction.ServerAddress
+		}
+		restrictions = append(restrictions, m)
+	}
+	return restrictions, nil
+}
+
+// getPodCIDRs return the pod CIDR ranges of the configuration or of the nodes
+func getPodCIDRs(ctx context.Context, r client.Client) ([]string, error) {
+	if cidrs := config.GetPodCIDRs(); len(cidrs) != 0 {
+		return cidrs, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	var cidrs []string
+	for _, node := range nodes.Items {
+		ranges := node.Spec.PodCIDRs
+		if len(ranges) == 0 && node.Spec.PodCIDR != "" {
+			ranges = []string{node.Spec.PodCIDR}
+		}
+		for _, cidr := range ranges {
+			if !contains(cidrs, cidr) {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil, errors.New("no pod CIDR set on the nodes, set podCIDRs in the operator configuration")
+	}
+	sort.Strings(cidrs)
+	return cidrs, nil
+}
+
+// getPodIPs return the IP addresses of the pods running in the namespace
+func getPodIPs(ctx context.Context, r client.Client, namespace string) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var ips []string
+	for _, po := range pods.Items {
+		if po.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, ip := range po.Status.PodIPs {
+			if ip.IP != "" && !contains(ips, ip.IP) {
+				ips = append(ips, ip.IP)
+			}
+		}
+		if len(po.Status.PodIPs) == 0 && po.Status.PodIP != "" && !contains(ips, po.Status.PodIP) {
+			ips = append(ips, po.Status.PodIP)
+		}
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// isSameRestrictions return whether the authentication restrictions of the user in the database are the resolved ones
+func isSameRestrictions(current []Restriction, wanted []bson.M) bool {
+	if len(current) != len(wanted) {
+		return false
+	}
+	for i, restriction := range wanted {
+		clientSource, _ := restriction["clientSource"].([]string)
+		serverAddress, _ := restriction["serverAddress"].([]string)
+		if !isSameList(current[i].ClientSource, clientSource) || !isSameList(current[i].ServerAddress, serverAddress) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSameList(current, wanted []string) bool {
+	if len(current) != len(wanted) {
+		return false
+	}
+	for i := range wanted {
+		if current[i] != wanted[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, elem := range list {
+		if elem == value {
+			return true
+		}
+	}
+	return false
+}
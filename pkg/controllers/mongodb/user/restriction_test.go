@@ -0,0 +1,140 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	"github.com/w6d-io/mongodb/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Restriction", func() {
+	var ctx context.Context
+	var user *db.MongoDBUser
+	var dir string
+	setConfig := func(content string) {
+		filename := filepath.Join(dir, "config.yaml")
+		Expect(ioutil.WriteFile(filename, []byte(content), 0600)).To(Succeed())
+		Expect(config.New(filename)).To(Succeed())
+	}
+	pod := func(name, namespace, ip string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+		}
+	}
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		dir, err = ioutil.TempDir("", "restriction")
+		Expect(err).ToNot(HaveOccurred())
+		setConfig("{}")
+		user = &db.MongoDBUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       db.MongoDBUserSpec{Username: "test"},
+		}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("returns an empty list without restriction", func() {
+		restrictions, err := GetAuthenticationRestrictions(ctx, fake.NewClientBuilder().Build(), user)
+		Expect(err).To(Succeed())
+		Expect(restrictions).ToNot(BeNil())
+		Expect(restrictions).To(BeEmpty())
+	})
+	It("keeps the addresses of the spec", func() {
+		user.Spec.AuthenticationRestrictions = []db.AuthenticationRestriction{
+			{ClientSource: []string{"10.0.0.1"}, ServerAddress: []string{"10.1.0.0/16"}},
+		}
+		Expect(GetAuthenticationRestrictions(ctx, fake.NewClientBuilder().Build(), user)).To(Equal([]bson.M{
+			{"clientSource": []string{"10.0.0.1"}, "serverAddress": []string{"10.1.0.0/16"}},
+		}))
+	})
+	It("adds the IP addresses of the pods running in the namespace", func() {
+		user.Spec.AuthenticationRestrictions = []db.AuthenticationRestriction{
+			{ClientSourceFrom: db.ClientSourceFromNamespacePods},
+		}
+		r := fake.NewClientBuilder().WithObjects(
+			pod("b", "default", "10.0.0.2", corev1.PodRunning),
+			pod("a", "default", "10.0.0.1", corev1.PodRunning),
+			pod("c", "default", "10.0.0.3", corev1.PodPending),
+			pod("d", "other", "10.0.0.4", corev1.PodRunning),
+		).Build()
+		Expect(GetAuthenticationRestrictions(ctx, r, user)).To(Equal([]bson.M{
+			{"clientSource": []string{"10.0.0.1", "10.0.0.2"}},
+		}))
+	})
+	It("falls back to the loopback address when no pod is running", func() {
+		user.Spec.AuthenticationRestrictions = []db.AuthenticationRestriction{
+			{ClientSourceFrom: db.ClientSourceFromNamespacePods},
+		}
+		Expect(GetAuthenticationRestrictions(ctx, fake.NewClientBuilder().Build(), user)).To(Equal([]bson.M{
+			{"clientSource": []string{LoopbackAddress}},
+		}))
+	})
+	It("adds the pod CIDRs of the configuration before the ones of the nodes", func() {
+		user.Spec.AuthenticationRestrictions = []db.AuthenticationRestriction{
+			{ClientSourceFrom: db.ClientSourceFromPodCIDR},
+		}
+		r := fake.NewClientBuilder().WithObjects(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: corev1.NodeSpec{PodCIDR: "10.244.1.0/24"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: corev1.NodeSpec{PodCIDRs: []string{"10.244.0.0/24"}}},
+		).Build()
+		Expect(GetAuthenticationRestrictions(ctx, r, user)).To(Equal([]bson.M{
+			{"clientSource": []string{"10.244.0.0/24", "10.244.1.0/24"}},
+		}))
+		setConfig("podCIDRs:\n- 10.244.0.0/16\n")
+		Expect(GetAuthenticationRestrictions(ctx, r, user)).To(Equal([]bson.M{
+			{"clientSource": []string{"10.244.0.0/16"}},
+		}))
+	})
+	It("fails when the pod CIDRs are unknown", func() {
+		user.Spec.AuthenticationRestrictions = []db.AuthenticationRestriction{
+			{ClientSourceFrom: db.ClientSourceFromPodCIDR},
+		}
+		_, err := GetAuthenticationRestrictions(ctx, fake.NewClientBuilder().Build(), user)
+		Expect(err).To(HaveOccurred())
+	})
+	It("compares the restrictions of the database with the resolved ones", func() {
+		wanted := []bson.M{
+			{"clientSource": []string{"10.0.0.1", "10.0.0.2"}},
+			{"serverAddress": []string{"10.1.0.0/16"}},
+		}
+		Expect(isSameRestrictions([]Restriction{
+			{ClientSource: []string{"10.0.0.1", "10.0.0.2"}},
+			{ServerAddress: []string{"10.1.0.0/16"}},
+		}, wanted)).To(BeTrue())
+		Expect(isSameRestrictions([]Restriction{
+			{ClientSource: []string{"10.0.0.1"}},
+			{ServerAddress: []string{"10.1.0.0/16"}},
+		}, wanted)).To(BeFalse())
+		Expect(isSameRestrictions(nil, wanted)).To(BeFalse())
+		Expect(isSameRestrictions(nil, []bson.M{})).To(BeTrue())
+	})
+})
@@ -16,13 +16,40 @@ Created on 10/04/2021
 */
 package user
 
+import "go.mongodb.org/mongo-driver/bson"
+
 type User struct {
-	User       string     `bson:"user"`
-	CustomData CustomData `bson:"customData"`
+	User                       string        `bson:"user"`
+	CustomData                 CustomData    `bson:"customData"`
+	Roles                      []Role        `bson:"roles"`
+	AuthenticationRestrictions []Restriction `bson:"authenticationRestrictions"`
 }
 
 type CustomData struct {
-	ParentID string `bson:"parentID"`
+	ParentID string `bson:"parentID,omitempty"`
+	// Others holds the fields kept by the users of the account
+	Others bson.M `bson:",inline"`
+}
+
+type Role struct {
+	Role string `bson:"role"`
+	DB   string `bson:"db"`
+}
+
+// Restriction is an authentication restriction of a user in the database
+type Restriction struct {
+	ClientSource  []string `bson:"clientSource,omitempty"`
+	ServerAddress []string `bson:"serverAddress,omitempty"`
+}
+
+// Drift lists what differs between the user in the database and its spec
+type Drift struct {
+	Roles      bool
+	CustomData bool
+	Password   bool
+	// Stale reports that the user is missing, handled by another resource or that the addresses of its
+	// restrictions changed. It is written again without being a drift
+	Stale bool
 }
 
 type Response struct {
@@ -28,6 +28,7 @@ import (
 	"github.com/w6d-io/mongodb/internal/util"
 	"github.com/w6d-io/mongodb/pkg/k8s/secret"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -35,37 +36,48 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func Create(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
-	log := util.GetLog(ctx, user).WithName("User").WithName("Create")
-	log.V(1).Info("create MongoDB user")
-	ok, err := IsUserExist(ctx, r, user)
-	if err != nil {
-		log.Error(err, "check user exist failed")
-		return err
-	}
-	if ok {
-		return Update(ctx, r, user)
-	}
+// GetClient return a client of the instance of the user, shared by the operations of a reconcile
+func GetClient(ctx context.Context, r client.Client, user *db.MongoDBUser) (*mongo.Client, error) {
+	log := util.GetLog(ctx, user).WithName("User").WithName("GetClient")
 	mdb, err := GetMongoDB(ctx, r, user)
 	if err != nil {
 		log.Error(err, "get MongoDB failed")
-		return err
+		return nil, err
 	}
 	c, err := mongodb.GetClient(ctx, r, mdb)
 	if err != nil {
 		log.Error(err, "get MongoDB client")
-		return err
+		return nil, err
 	}
 	if err = c.Ping(ctx, nil); err != nil {
 		log.Error(err, "ping db failed")
-		return err
+		_ = c.Disconnect(ctx)
+		return nil, err
 	}
+	return c, nil
+}
 
+func Create(ctx context.Context, r client.Client, c *mongo.Client, user *db.MongoDBUser) error {
+	log := util.GetLog(ctx, user).WithName("User").WithName("Create")
+	log.V(1).Info("create MongoDB user")
+	ok, err := IsUserExist(ctx, c, user)
+	if err != nil {
+		log.Error(err, "check user exist failed")
+		return err
+	}
+	if ok {
+		return Update(ctx, r, c, user)
+	}
 	passwd := GetUserPassword(ctx, r, user)
 	if passwd == "" {
 		log.Error(nil, "password cannot be empty")
 		return errors.New("password cannot be empty")
 	}
+	restrictions, err := GetAuthenticationRestrictions(ctx, r, user)
+	if err != nil {
+		log.Error(err, "get authentication restrictions failed")
+		return err
+	}
 	d := c.Database("admin")
 	res := d.RunCommand(ctx, bson.D{
 		{Key: "createUser", Value: user.Spec.Username},
@@ -74,6 +86,7 @@ func Create(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
 		}},
 		{Key: "pwd", Value: passwd},
 		{Key: "roles", Value: GetPrivileges(ctx, user)},
+		{Key: "authenticationRestrictions", Value: restrictions},
 	})
 	if res.Err() != nil {
 		log.Error(res.Err(), "create user failed")
@@ -82,42 +95,35 @@ func Create(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
 	return nil
 }
 
-func Update(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
+func Update(ctx context.Context, r client.Client, c *mongo.Client, user *db.MongoDBUser) error {
 	log := util.GetLog(ctx, user).WithName("User").WithName("Update")
 	log.V(1).Info("update MongoDB user")
-	users, err := GetUser(ctx, r, user)
+	users, err := GetUser(ctx, c, user)
 	if err != nil {
 		log.Error(err, "get user failed")
 		return err
 	}
-	if users[0].CustomData.ParentID != string(user.GetUID()) {
+	if !isOwner(users[0], user) {
 		log.Error(nil, "this user is already handle by an other resource", "user", user.Spec.Username)
 		return errors.New("a user can be handle only by one resource")
 	}
-	mdb, err := GetMongoDB(ctx, r, user)
-	if err != nil {
-		log.Error(err, "get MongoDB failed")
-		return err
-	}
-	c, err := mongodb.GetClient(ctx, r, mdb)
-	if err != nil {
-		log.Error(err, "get MongoDB client")
-		return err
-	}
-	if err = c.Ping(ctx, nil); err != nil {
-		log.Error(err, "ping db failed")
-		return err
-	}
 	passwd := GetUserPassword(ctx, r, user)
 	if passwd == "" {
 		log.Error(nil, "password cannot be empty")
 		return errors.New("password cannot be empty")
 	}
+	restrictions, err := GetAuthenticationRestrictions(ctx, r, user)
+	if err != nil {
+		log.Error(err, "get authentication restrictions failed")
+		return err
+	}
 	d := c.Database("admin")
 	res := d.RunCommand(ctx, bson.D{
 		{Key: "updateUser", Value: user.Spec.Username},
+		{Key: "customData", Value: GetCustomData(users[0], user)},
 		{Key: "pwd", Value: passwd},
 		{Key: "roles", Value: GetPrivileges(ctx, user)},
+		{Key: "authenticationRestrictions", Value: restrictions},
 	})
 	if res.Err() != nil {
 		log.Error(res.Err(), "update user failed")
@@ -127,15 +133,10 @@ func Update(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
 	return nil
 }
 
-func Delete(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
+func Delete(ctx context.Context, r client.Client, c *mongo.Client, user *db.MongoDBUser) error {
 	log := util.GetLog(ctx, user).WithName("User").WithName("Delete").WithValues("username", user.Spec.Username)
 	log.V(1).Info("delete MongoDB user")
-	mdb, err := GetMongoDB(ctx, r, user)
-	if err != nil {
-		log.Error(err, "get MongoDB failed")
-		return err
-	}
-	users, err := GetUser(ctx, r, user)
+	users, err := GetUser(ctx, c, user)
 	if err != nil {
 		log.Error(err, "get user failed")
 		return err
@@ -144,19 +145,10 @@ func Delete(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
 	if len(users) == 0 {
 		return nil
 	}
-	if users[0].CustomData.ParentID != string(user.GetUID()) {
+	if !isOwner(users[0], user) {
 		log.V(1).Info("skipped deletion", "user", user.Spec.Username)
 		return nil
 	}
-	c, err := mongodb.GetClient(ctx, r, mdb)
-	if err != nil {
-		log.Error(err, "get MongoDB client")
-		return err
-	}
-	if err = c.Ping(ctx, nil); err != nil {
-		log.Error(err, "ping db failed")
-		return err
-	}
 	passwd := GetUserPassword(ctx, r, user)
 	if passwd == "" {
 		log.Error(nil, "password cannot be empty")
@@ -176,6 +168,17 @@ func Delete(ctx context.Context, r client.Client, user *db.MongoDBUser) error {
 	return nil
 }
 
+// GetCustomData return the custom data of the user in the database with the parentID of the resource,
+// the other fields are kept
+func GetCustomData(usr User, user *db.MongoDBUser) bson.M {
+	data := bson.M{}
+	for key, value := range usr.CustomData.Others {
+		data[key] = value
+	}
+	data["parentID"] = string(user.UID)
+	return data
+}
+
 // GetMongoDB return the mongoDB resource referenced by the name
 func GetMongoDB(ctx context.Context, r client.Client, user *db.MongoDBUser) (*db.MongoDB, error) {
 	correlationID := ctx.Value("correlation_id")
@@ -226,11 +229,11 @@ func IsNotFound(err error) bool {
 }
 
 // IsUserExist check if user exist in database
-func IsUserExist(ctx context.Context, r client.Client, user *db.MongoDBUser) (bool, error) {
+func IsUserExist(ctx context.Context, c *mongo.Client, user *db.MongoDBUser) (bool, error) {
 	log := util.GetLog(ctx, user).WithName("User").WithName("IsUserExist")
 	log.V(1).Info("Is MongoDB user exist")
 
-	rsp, err := GetUser(ctx, r, user)
+	rsp, err := GetUser(ctx, c, user)
 	if err != nil {
 		return false, err
 	}
@@ -254,41 +257,27 @@ func GetPrivileges(ctx context.Context, user *db.MongoDBUser) []bson.M {
 	return p
 }
 
-func GetUsers(ctx context.Context, r client.Client, user *db.MongoDBUser) (*Response, error) {
+func GetUsers(ctx context.Context, c *mongo.Client, user *db.MongoDBUser) (*Response, error) {
 	log := util.GetLog(ctx, user).WithName("User").WithName("GetUsers")
 	log.V(1).Info("get MongoDB users")
-	mdb, err := GetMongoDB(ctx, r, user)
-	if err != nil {
-		log.Error(err, "get MongoDB failed")
-		return nil, err
-	}
-	c, err := mongodb.GetClient(ctx, r, mdb)
-	if err != nil {
-		log.Error(err, "get MongoDB client")
-		return nil, err
-	}
-	if err = c.Ping(ctx, nil); err != nil {
-		log.Error(err, "ping db failed")
-		return nil, err
-	}
-
 	d := c.Database("admin")
 	res := d.RunCommand(ctx, bson.D{
 		{Key: "usersInfo", Value: 1},
+		{Key: "showAuthenticationRestrictions", Value: true},
 	})
 	var response = new(Response)
-	err = res.Decode(response)
+	err := res.Decode(response)
 	if err != nil {
 		return nil, err
 	}
 	return response, nil
 }
 
-func GetUser(ctx context.Context, r client.Client, user *db.MongoDBUser) ([]User, error) {
+func GetUser(ctx context.Context, c *mongo.Client, user *db.MongoDBUser) ([]User, error) {
 	log := util.GetLog(ctx, user).WithName("User").WithName("GetUsers")
 	log.V(1).Info("get MongoDB user")
 
-	rsp, err := GetUsers(ctx, r, user)
+	rsp, err := GetUsers(ctx, c, user)
 	if err != nil {
 		log.Error(err, "get users failed")
 		return nil, err
@@ -301,3 +290,18 @@ func GetUser(ctx context.Context, r client.Client, user *db.MongoDBUser) ([]User
 	}
 	return users, nil
 }
+
+// ListByMongoDB return the users referencing the instance through their dbref
+func ListByMongoDB(ctx context.Context, r client.Client, mongoDB *db.MongoDB) ([]db.MongoDBUser, error) {
+	list := &db.MongoDBUserList{}
+	if err := r.List(ctx, list, client.InNamespace(mongoDB.Namespace)); err != nil {
+		return nil, err
+	}
+	var users []db.MongoDBUser
+	for _, usr := range list.Items {
+		if usr.Spec.DBRef != nil && usr.Spec.DBRef.Name == mongoDB.Name {
+			users = append(users, usr)
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// GetSecretNames indexes the user by the name of the secret holding its password
+func GetSecretNames(obj client.Object) []string {
+	from := obj.(*db.MongoDBUser).Spec.Password.ValueFrom
+	if from == nil || from.SecretKeyRef == nil {
+		return nil
+	}
+	return []string{from.SecretKeyRef.Name}
+}
+
+// GetDBRefNames indexes the user by the name of its instance
+func GetDBRefNames(obj client.Object) []string {
+	dbRef := obj.(*db.MongoDBUser).Spec.DBRef
+	if dbRef == nil {
+		return nil
+	}
+	return []string{dbRef.Name}
+}
+
+// GetClientSourcesFrom indexes the user by the addresses of the cluster it authenticates from
+func GetClientSourcesFrom(obj client.Object) []string {
+	var sources []string
+	for _, restriction := range obj.(*db.MongoDBUser).Spec.AuthenticationRestrictions {
+		if restriction.ClientSourceFrom != "" {
+			sources = append(sources, string(restriction.ClientSourceFrom))
+		}
+	}
+	return sources
+}
+
+// GetRequests return the function mapping an object to the users of its namespace whose indexed field
+// matches the value of the object
+func GetRequests(r client.Client, index string, getValue func(client.Object) string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		log := ctrl.Log.WithName("User").WithName("GetRequests")
+		list := &db.MongoDBUserList{}
+		if err := r.List(context.Background(), list, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{index: getValue(obj)}); err != nil {
+			log.Error(err, "list users failed", "index", index, "name", obj.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, usr := range list.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: usr.Name, Namespace: usr.Namespace},
+			})
+		}
+		return requests
+	}
+}
+
+// BecomesReady filters the updates of the instances reaching the ready phase
+func BecomesReady() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			previous, ok := e.ObjectOld.(*db.MongoDB)
+			if !ok {
+				return false
+			}
+			current, ok := e.ObjectNew.(*db.MongoDB)
+			if !ok {
+				return false
+			}
+			return previous.Status.Phase != db.MongoDBPhaseReady && current.Status.Phase == db.MongoDBPhaseReady
+		},
+	}
+}
+
+// PodIPChanges filters the pods whose addresses are assigned or released
+func PodIPChanges() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			previous, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			current, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return previous.Status.PodIP != current.Status.PodIP || previous.Status.Phase != current.Status.Phase
+		},
+	}
+}
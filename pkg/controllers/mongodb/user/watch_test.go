@@ -0,0 +1,174 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package user
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+// indexedClient lists the users matching the field selector through the indexers, as the cache of the manager does
+type indexedClient struct {
+	client.Client
+	indexers map[string]client.IndexerFunc
+}
+
+func (c indexedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if err := c.Client.List(ctx, list, client.InNamespace(listOpts.Namespace)); err != nil {
+		return err
+	}
+	users := list.(*db.MongoDBUserList)
+	var items []db.MongoDBUser
+	for _, usr := range users.Items {
+		matched := true
+		for _, requirement := range listOpts.FieldSelector.Requirements() {
+			matched = matched && contains(c.indexers[requirement.Field](&usr), requirement.Value)
+		}
+		if matched {
+			items = append(items, usr)
+		}
+	}
+	users.Items = items
+	return nil
+}
+
+var _ = Describe("Watch", func() {
+	Context("requests", func() {
+		var r client.Client
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(db.AddToScheme(scheme)).To(Succeed())
+			fromSecret := func(name string) db.Password {
+				return db.Password{ValueFrom: &db.PasswordFrom{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "password",
+				}}}
+			}
+			r = indexedClient{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					&db.MongoDBUser{
+						ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+						Spec: db.MongoDBUserSpec{
+							DBRef:    &corev1.LocalObjectReference{Name: "mongodb"},
+							Password: fromSecret("app"),
+							AuthenticationRestrictions: []db.AuthenticationRestriction{
+								{ClientSourceFrom: db.ClientSourceFromNamespacePods},
+							},
+						},
+					},
+					&db.MongoDBUser{
+						ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+						Spec: db.MongoDBUserSpec{
+							DBRef:    &corev1.LocalObjectReference{Name: "mongodb"},
+							Password: fromSecret("report"),
+						},
+					},
+					&db.MongoDBUser{
+						ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "other"},
+						Spec: db.MongoDBUserSpec{
+							DBRef:    &corev1.LocalObjectReference{Name: "mongodb"},
+							Password: fromSecret("app"),
+						},
+					},
+				).Build(),
+				indexers: map[string]client.IndexerFunc{
+					"secret":     GetSecretNames,
+					"dbref":      GetDBRefNames,
+					"clientFrom": GetClientSourcesFrom,
+				},
+			}
+		})
+		It("maps the secret to the users of its namespace holding their password in it", func() {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+			Expect(GetRequests(r, "secret", client.Object.GetName)(secret)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}},
+			}))
+			secret.Name = "unused"
+			Expect(GetRequests(r, "secret", client.Object.GetName)(secret)).To(BeEmpty())
+		})
+		It("maps the instance to its users", func() {
+			mongoDB := &db.MongoDB{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"}}
+			Expect(GetRequests(r, "dbref", client.Object.GetName)(mongoDB)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "report", Namespace: "default"}},
+			))
+		})
+		It("maps the pod to the users authenticating from the pods of the namespace", func() {
+			po := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: "default"}}
+			getValue := func(client.Object) string { return string(db.ClientSourceFromNamespacePods) }
+			Expect(GetRequests(r, "clientFrom", getValue)(po)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}},
+			}))
+		})
+	})
+	Context("instance ready", func() {
+		update := func(previous, current db.MongoDBPhase) event.UpdateEvent {
+			return event.UpdateEvent{
+				ObjectOld: &db.MongoDB{Status: db.MongoDBStatus{Phase: previous}},
+				ObjectNew: &db.MongoDB{Status: db.MongoDBStatus{Phase: current}},
+			}
+		}
+		It("keeps the instance becoming ready", func() {
+			Expect(BecomesReady().Update(update(db.MongoDBPhaseNotReady, db.MongoDBPhaseReady))).To(BeTrue())
+			Expect(BecomesReady().Update(update("", db.MongoDBPhaseReady))).To(BeTrue())
+		})
+		It("ignores the other updates", func() {
+			Expect(BecomesReady().Update(update(db.MongoDBPhaseReady, db.MongoDBPhaseReady))).To(BeFalse())
+			Expect(BecomesReady().Update(update(db.MongoDBPhaseReady, db.MongoDBPhaseNotReady))).To(BeFalse())
+			Expect(BecomesReady().Update(update(db.MongoDBPhaseProvisioning, db.MongoDBPhaseNotReady))).To(BeFalse())
+		})
+		It("ignores the creation, deletion and generic events", func() {
+			mongoDB := &db.MongoDB{Status: db.MongoDBStatus{Phase: db.MongoDBPhaseReady}}
+			Expect(BecomesReady().Create(event.CreateEvent{Object: mongoDB})).To(BeFalse())
+			Expect(BecomesReady().Delete(event.DeleteEvent{Object: mongoDB})).To(BeFalse())
+			Expect(BecomesReady().Generic(event.GenericEvent{Object: mongoDB})).To(BeFalse())
+		})
+	})
+	Context("pod addresses", func() {
+		pod := func(ip string, phase corev1.PodPhase) *corev1.Pod {
+			return &corev1.Pod{Status: corev1.PodStatus{PodIP: ip, Phase: phase}}
+		}
+		It("keeps the pod whose address is assigned or released", func() {
+			Expect(PodIPChanges().Update(event.UpdateEvent{
+				ObjectOld: pod("", corev1.PodPending), ObjectNew: pod("10.0.0.1", corev1.PodPending),
+			})).To(BeTrue())
+			Expect(PodIPChanges().Update(event.UpdateEvent{
+				ObjectOld: pod("10.0.0.1", corev1.PodRunning), ObjectNew: pod("10.0.0.1", corev1.PodSucceeded),
+			})).To(BeTrue())
+			Expect(PodIPChanges().Delete(event.DeleteEvent{Object: pod("10.0.0.1", corev1.PodRunning)})).To(BeTrue())
+		})
+		It("ignores the other updates", func() {
+			Expect(PodIPChanges().Update(event.UpdateEvent{
+				ObjectOld: pod("10.0.0.1", corev1.PodRunning), ObjectNew: pod("10.0.0.1", corev1.PodRunning),
+			})).To(BeFalse())
+			Expect(PodIPChanges().Create(event.CreateEvent{Object: pod("10.0.0.1", corev1.PodRunning)})).To(BeFalse())
+		})
+	})
+})
@@ -18,6 +18,7 @@ package configmap
 
 import (
 	"context"
+	"reflect"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -34,25 +35,31 @@ func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme,
 	log := util.GetLog(ctx, mongoDB).WithName("Create").WithName("configmap")
 	var err error
 
-	cm := &corev1.ConfigMap{}
-	log.V(1).Info("create configmap")
-	err = r.Get(ctx, getTypesNamespacedNameScript(mongoDB), cm)
+	cm := getScriptConfigMap(ctx, scheme, mongoDB)
+	if cm == nil {
+		log.Error(nil, "get configmap return nil")
+		return &Error{Cause: nil, Detail: "get configmap return nil"}
+	}
+	current := &corev1.ConfigMap{}
+	err = r.Get(ctx, getTypesNamespacedNameScript(mongoDB), current)
 	if err != nil && errors.IsNotFound(err) {
-		cm = getScriptConfigMap(ctx, scheme, mongoDB)
-		if cm == nil {
-			log.Error(nil, "get configmap return nil")
-			return &Error{Cause: nil, Detail: "get configmap return nil"}
-		}
+		log.V(1).Info("create configmap")
 		err = r.Create(ctx, cm)
 		if err != nil && !errors.IsAlreadyExists(err) {
 			log.Error(err, "fail to create configmap")
 			return &Error{Cause: err, Detail: "fail to create configmap"}
 		}
+		return nil
 	} else if err != nil {
 		log.Error(err, "fail to get configmap")
 		return &Error{Cause: err, Detail: "failed to get configmap"}
 	}
-	err = r.Update(ctx, cm)
+	if reflect.DeepEqual(current.Data, cm.Data) {
+		return nil
+	}
+	log.V(1).Info("update configmap")
+	current.Data = cm.Data
+	err = r.Update(ctx, current)
 	if err != nil {
 		log.Error(err, "fail to update configmap")
 		return &Error{Cause: err, Detail: "fail to update configmap"}
@@ -17,67 +17,66 @@ Created on 02/04/2021
 package configmap
 
 const (
-	AutoDiscovery string = `|-
-    #!/bin/bash
-    SVC_NAME="${MY_POD_NAME}-external"
-    # Auxiliary functions
-    retry_while() {
-        local -r cmd="${1:?cmd is missing}"
-        local -r retries="${2:-12}"
-        local -r sleep_time="${3:-5}"
-        local return_value=1
-        read -r -a command <<< "$cmd"
-        for ((i = 1 ; i <= retries ; i+=1 )); do
-            "${command[@]}" && return_value=0 && break
-            sleep "$sleep_time"
-        done
-        return $return_value
-    }
-    k8s_svc_lb_ip() {
-        local namespace=${1:?namespace is missing}
-        local service=${2:?service is missing}
-        local service_ip=$(kubectl get svc "$service" -n "$namespace" -o jsonpath="{.status.loadBalancer.ingress[0].ip}")
-        local service_hostname=$(kubectl get svc "$service" -n "$namespace" -o jsonpath="{.status.loadBalancer.ingress[0].hostname}")
-        if [[ -n ${service_ip} ]]; then
-            echo "${service_ip}"
-        else
-            echo "${service_hostname}"
-        fi
-    }
-    k8s_svc_lb_ip_ready() {
-        local namespace=${1:?namespace is missing}
-        local service=${2:?service is missing}
-        [[ -n "$(k8s_svc_lb_ip "$namespace" "$service")" ]]
-    }
-    # Wait until LoadBalancer IP is ready
-    retry_while "k8s_svc_lb_ip_ready %s $SVC_NAME" || exit 1
-    # Obtain LoadBalancer external IP
-    k8s_svc_lb_ip "%s" "$SVC_NAME" | tee "$SHARED_FILE"
-  {{- end }}
-`
-	Setup string = `|-
-    #!/bin/bash
-    echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
-    if [[ "$MY_POD_NAME" = "%s" ]]; then
-        echo "Pod name matches initial primary pod name, configuring node as a primary"
-        export MONGODB_REPLICA_SET_MODE="primary"
-    else
-        echo "Pod name doesn't match initial primary pod name, configuring node as a secondary"
-        export MONGODB_REPLICA_SET_MODE="secondary"
-        export MONGODB_INITIAL_PRIMARY_ROOT_PASSWORD="$MONGODB_ROOT_PASSWORD"
-        export MONGODB_INITIAL_PRIMARY_PORT_NUMBER="$MONGODB_PORT_NUMBER"
-        export MONGODB_ROOT_PASSWORD="" MONGODB_USERNAME="" MONGODB_DATABASE="" MONGODB_PASSWORD=""
-    fi
-    exec /opt/bitnami/scripts/mongodb/entrypoint.sh /opt/bitnami/scripts/mongodb/run.sh
-`
-	SetupHidden = `|-
-    #!/bin/bash
-    echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
-    echo "Configuring node as a hidden node"
-    export MONGODB_REPLICA_SET_MODE="hidden"
+	Setup string = `#!/bin/bash
+echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
+if [[ "$MY_POD_NAME" = "%s" ]]; then
+    echo "Pod name matches initial primary pod name, configuring node as a primary"
+    export MONGODB_REPLICA_SET_MODE="primary"
+else
+    echo "Pod name doesn't match initial primary pod name, configuring node as a secondary"
+    export MONGODB_REPLICA_SET_MODE="secondary"
     export MONGODB_INITIAL_PRIMARY_ROOT_PASSWORD="$MONGODB_ROOT_PASSWORD"
     export MONGODB_INITIAL_PRIMARY_PORT_NUMBER="$MONGODB_PORT_NUMBER"
     export MONGODB_ROOT_PASSWORD="" MONGODB_USERNAME="" MONGODB_DATABASE="" MONGODB_PASSWORD=""
-    exec /opt/bitnami/scripts/mongodb/entrypoint.sh /opt/bitnami/scripts/mongodb/run.sh
-{{- end }}`
+fi
+exec /opt/bitnami/scripts/mongodb/entrypoint.sh /opt/bitnami/scripts/mongodb/run.sh
+`
+	SetupHidden = `#!/bin/bash
+echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
+echo "Configuring node as a hidden node"
+export MONGODB_REPLICA_SET_MODE="hidden"
+export MONGODB_INITIAL_PRIMARY_ROOT_PASSWORD="$MONGODB_ROOT_PASSWORD"
+export MONGODB_INITIAL_PRIMARY_PORT_NUMBER="$MONGODB_PORT_NUMBER"
+export MONGODB_ROOT_PASSWORD="" MONGODB_USERNAME="" MONGODB_DATABASE="" MONGODB_PASSWORD=""
+exec /opt/bitnami/scripts/mongodb/entrypoint.sh /opt/bitnami/scripts/mongodb/run.sh
+`
+	SetupArbiter = `#!/bin/bash
+echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
+echo "Configuring node as an arbiter"
+export MONGODB_REPLICA_SET_MODE="arbiter"
+export MONGODB_INITIAL_PRIMARY_ROOT_PASSWORD="$MONGODB_ROOT_PASSWORD"
+export MONGODB_INITIAL_PRIMARY_PORT_NUMBER="$MONGODB_PORT_NUMBER"
+export MONGODB_ROOT_PASSWORD="" MONGODB_USERNAME="" MONGODB_DATABASE="" MONGODB_PASSWORD=""
+exec /opt/bitnami/scripts/mongodb/entrypoint.sh /opt/bitnami/scripts/mongodb/run.sh
+`
+	// OfficialSetup starts mongod from the configuration file, the first data-bearing member
+	// initiates the replica set and creates the root user through the localhost exception
+	OfficialSetup = `#!/bin/bash
+set -e
+echo "Advertised Hostname: $MONGODB_ADVERTISED_HOSTNAME"
+config=/scripts/` + MongodConfKey + `
+rm -f ` + KeyFilePath + `
+printf '%%s' "$MONGODB_REPLICA_SET_KEY" > ` + KeyFilePath + `
+chmod 0400 ` + KeyFilePath + `
+if [[ -n "$MONGODB_ENCRYPTION_KEY_FILE" ]]; then
+    rm -f ` + EncryptionKeyFilePath + `
+    cp "$MONGODB_ENCRYPTION_KEY_FILE" ` + EncryptionKeyFilePath + `
+    chmod 0400 ` + EncryptionKeyFilePath + `
+fi
+if [[ "$MY_POD_NAME" = "%[1]s" && ! -f ` + DataPath + `/.replicaset-initialized ]]; then
+    echo "Pod name matches initial primary pod name, initiating the replica set"
+    mongod --config "$config" --fork --logpath /tmp/mongod-setup.log
+    # the password is read from the environment by the shell, mongosh or the legacy one, so that it is
+    # not part of the command line
+    $MONGODB_SHELL --quiet $MONGODB_SHELL_FLAGS --eval "
+try { rs.initiate({_id: '%[2]s', members: [{_id: 0, host: '$MONGODB_ADVERTISED_HOSTNAME:$MONGODB_PORT_NUMBER'}]}) } catch (e) { print(e) }
+while (!db.isMaster().ismaster) { sleep(1000) }
+var password = typeof process !== 'undefined' ? process.env.MONGODB_ROOT_PASSWORD : _getEnv('MONGODB_ROOT_PASSWORD')
+try { db.getSiblingDB('admin').createUser({user: 'root', pwd: password, roles: ['root']}) } catch (e) { print(e) }
+"
+    mongod --config "$config" --shutdown
+    touch ` + DataPath + `/.replicaset-initialized
+fi
+exec mongod --config "$config"
+`
 )
@@ -19,6 +19,9 @@ package configmap
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/w6d-io/mongodb/internal/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -49,15 +52,11 @@ func getScriptConfigMap(ctx context.Context, scheme *runtime.Scheme, mongoDB *db
 	log := util.GetLog(ctx, mongoDB)
 	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      mongoDB.Name + "-scripts",
+			Name:      GetScriptsName(mongoDB),
 			Namespace: mongoDB.Namespace,
 			Labels:    util.LabelsForMongoDB(mongoDB.Name),
 		},
-		Data: map[string]string{
-			"auto-discovery.sh": fmt.Sprintf(AutoDiscovery, mongoDB.Namespace, mongoDB.Namespace),
-			"setup.sh":          fmt.Sprintf(Setup, getFullname(mongoDB)),
-			"setup-hidden.sh":   SetupHidden,
-		},
+		Data: getScripts(mongoDB),
 	}
 	if err := ctrl.SetControllerReference(mongoDB, cm, scheme); err != nil {
 		log.Error(err, "set owner failed")
@@ -65,10 +64,104 @@ func getScriptConfigMap(ctx context.Context, scheme *runtime.Scheme, mongoDB *db
 	}
 	return cm
 }
+
+// getScripts return the setup scripts of the flavor of the instance, the official and Percona images
+// share the same script for every kind of member and the configuration file of mongod
+func getScripts(mongoDB *db.MongoDB) map[string]string {
+	if mongoDB.Spec.GetFlavor() == db.FlavorBitnami {
+		return map[string]string{
+			SetupKey:        fmt.Sprintf(Setup, getFullname(mongoDB)),
+			SetupHiddenKey:  SetupHidden,
+			SetupArbiterKey: SetupArbiter,
+		}
+	}
+	setup := fmt.Sprintf(OfficialSetup, getFullname(mongoDB), db.MongoDBReplicaSetName)
+	return map[string]string{
+		SetupKey:        setup,
+		SetupHiddenKey:  setup,
+		SetupArbiterKey: setup,
+		MongodConfKey:   getMongodConf(mongoDB),
+	}
+}
+
+// getMongodConf return the configuration file of mongod with the Percona options of the Percona flavor
+func getMongodConf(mongoDB *db.MongoDB) string {
+	net := map[string]interface{}{
+		"port":      db.MongoDBPort,
+		"bindIpAll": true,
+	}
+	if mongoDB.Spec.TLS != nil {
+		net["tls"] = map[string]interface{}{
+			"mode":               "requireTLS",
+			"certificateKeyFile": "/certs/mongodb.pem",
+			"CAFile":             "/certs/mongodb-ca-cert",
+		}
+	}
+	security := map[string]interface{}{
+		"authorization": "enabled",
+		"keyFile":       KeyFilePath,
+	}
+	conf := map[string]interface{}{
+		"net": net,
+		"replication": map[string]interface{}{
+			"replSetName": db.MongoDBReplicaSetName,
+		},
+		"security": security,
+		"storage": map[string]interface{}{
+			"dbPath": DataPath,
+		},
+	}
+	if percona := mongoDB.Spec.Percona; percona != nil && mongoDB.Spec.GetFlavor() == db.FlavorPercona {
+		if percona.Encryption != nil {
+			security["enableEncryption"] = true
+			security["encryptionKeyFile"] = EncryptionKeyFilePath
+			if percona.Encryption.CipherMode != "" {
+				security["encryptionCipherMode"] = percona.Encryption.CipherMode
+			}
+		}
+		if percona.AuditLog != nil {
+			conf["auditLog"] = getAuditLog(percona.AuditLog)
+		}
+	}
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// getAuditLog return the auditLog section of the configuration, the file is written in the data directory
+func getAuditLog(auditLog *db.AuditLogSpec) map[string]interface{} {
+	destination := auditLog.Destination
+	if destination == "" {
+		destination = "file"
+	}
+	section := map[string]interface{}{
+		"destination": destination,
+	}
+	if destination == "file" {
+		format := auditLog.Format
+		if format == "" {
+			format = "JSON"
+		}
+		section["format"] = format
+		section["path"] = DataPath + "/auditLog." + strings.ToLower(format)
+	}
+	if auditLog.Filter != "" {
+		section["filter"] = auditLog.Filter
+	}
+	return section
+}
+
 func getFullname(mongoDB *db.MongoDB) string {
 	return fmt.Sprintf("%s-0", mongoDB.Name)
 }
 
 func getTypesNamespacedNameScript(mongoDB *db.MongoDB) types.NamespacedName {
-	return types.NamespacedName{Name: mongoDB.Name + "-scripts", Namespace: mongoDB.Namespace}
+	return types.NamespacedName{Name: GetScriptsName(mongoDB), Namespace: mongoDB.Namespace}
+}
+
+// GetScriptsName return the name of the scripts configmap
+func GetScriptsName(mongoDB *db.MongoDB) string {
+	return mongoDB.Name + "-scripts"
 }
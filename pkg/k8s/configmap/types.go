@@ -16,6 +16,21 @@ Created on 02/04/2021
 */
 package configmap
 
+const (
+	SetupKey        string = "setup.sh"
+	SetupHiddenKey  string = "setup-hidden.sh"
+	SetupArbiterKey string = "setup-arbiter.sh"
+
+	// MongodConfKey is the configuration file of mongod run by the official and Percona images
+	MongodConfKey string = "mongod.conf"
+	// DataPath is the data directory of the official and Percona images
+	DataPath string = "/data/db"
+	// KeyFilePath is the replica set key file written by the setup script
+	KeyFilePath string = "/tmp/keyfile"
+	// EncryptionKeyFilePath is the master key of the encryption at rest copied by the setup script
+	EncryptionKeyFilePath string = "/tmp/encryption-key"
+)
+
 type Error struct {
 	Cause  error
 	Detail string
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/service"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// GetDashboardName return the name of the dashboard configmap
+func GetDashboardName(mongoDB *db.MongoDB) string {
+	return mongoDB.Name + "-dashboard"
+}
+
+func createUpdateDashboard(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateUpdate").WithName("Dashboard")
+	var err error
+
+	cm := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: GetDashboardName(mongoDB), Namespace: mongoDB.Namespace}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "fail to get dashboard configmap")
+		return &Error{Cause: err, Detail: "fail to get dashboard configmap"}
+	}
+	found := err == nil
+	if !mongoDB.Spec.Monitoring.IsGrafanaDashboardEnabled() {
+		if found && metav1.IsControlledBy(cm, mongoDB) {
+			log.V(1).Info("delete dashboard configmap")
+			if err = r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "fail to delete dashboard configmap")
+				return &Error{Cause: err, Detail: "fail to delete dashboard configmap"}
+			}
+		}
+		return nil
+	}
+	dashboard := getDashboardConfigMap(ctx, scheme, mongoDB)
+	if dashboard == nil {
+		log.Error(nil, "get dashboard configmap return nil")
+		return &Error{Cause: nil, Detail: "get dashboard configmap return nil"}
+	}
+	if !found {
+		log.V(1).Info("create dashboard configmap")
+		if err = r.Create(ctx, dashboard); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "fail to create dashboard configmap")
+			return &Error{Cause: err, Detail: "fail to create dashboard configmap"}
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(cm, mongoDB) ||
+		(reflect.DeepEqual(cm.Labels, dashboard.Labels) && reflect.DeepEqual(cm.Data, dashboard.Data)) {
+		return nil
+	}
+	cm.Labels = dashboard.Labels
+	cm.Data = dashboard.Data
+	if err = r.Update(ctx, cm); err != nil {
+		log.Error(err, "fail to update dashboard configmap")
+		return &Error{Cause: err, Detail: "fail to update dashboard configmap"}
+	}
+	return nil
+}
+
+func getDashboardConfigMap(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB) *corev1.ConfigMap {
+	log := util.GetLog(ctx, mongoDB).WithName("GetDashboardConfigMap")
+	extraLabels := map[string]string{
+		DashboardLabel: "1",
+	}
+	if m := mongoDB.Spec.Monitoring; m != nil && m.GrafanaDashboard != nil {
+		for k, v := range m.GrafanaDashboard.Labels {
+			extraLabels[k] = v
+		}
+	}
+	data, err := json.MarshalIndent(getDashboard(mongoDB), "", "  ")
+	if err != nil {
+		log.Error(err, "marshal dashboard failed")
+		return nil
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetDashboardName(mongoDB),
+			Namespace: mongoDB.Namespace,
+			Labels:    mergeLabels(mongoDB.Name, extraLabels),
+		},
+		Data: map[string]string{
+			DashboardKey: string(data),
+		},
+	}
+	if err := ctrl.SetControllerReference(mongoDB, cm, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return cm
+}
+
+// getDashboard return a grafana dashboard scoped to the instance
+func getDashboard(mongoDB *db.MongoDB) map[string]interface{} {
+	selector := fmt.Sprintf(`namespace="%s",service="%s"`, mongoDB.Namespace, service.GetMetricsName(mongoDB))
+	panels := []map[string]interface{}{
+		getPanel(1, "Members up", "stat", fmt.Sprintf(`sum(mongodb_up{%s})`, selector), "{{pod}}"),
+		getPanel(2, "Connections", "timeseries", fmt.Sprintf(`mongodb_connections{%s,state="current"}`, selector), "{{pod}}"),
+		getPanel(3, "Operations", "timeseries", fmt.Sprintf(`sum by (type) (rate(mongodb_op_counters_total{%s}[5m]))`, selector), "{{type}}"),
+		getPanel(4, "Replication lag", "timeseries", fmt.Sprintf(`mongodb_mongod_replset_member_replication_lag{%s}`, selector), "{{name}}"),
+		getPanel(5, "Resident memory", "timeseries", fmt.Sprintf(`mongodb_memory{%s,type="resident"}`, selector), "{{pod}}"),
+		getPanel(6, "Disk usage", "timeseries",
+			fmt.Sprintf(`100 * kubelet_volume_stats_used_bytes{namespace="%s",persistentvolumeclaim=~"datadir-%s-[0-9]+"} / kubelet_volume_stats_capacity_bytes{namespace="%s",persistentvolumeclaim=~"datadir-%s-[0-9]+"}`,
+				mongoDB.Namespace, mongoDB.Name, mongoDB.Namespace, mongoDB.Name), "{{persistentvolumeclaim}}"),
+	}
+	return map[string]interface{}{
+		"title":         fmt.Sprintf("MongoDB %s/%s", mongoDB.Namespace, mongoDB.Name),
+		"uid":           util.AsSha256(mongoDB.Namespace + "/" + mongoDB.Name)[:12],
+		"tags":          []string{"mongodb", "db.w6d.io"},
+		"timezone":      "browser",
+		"schemaVersion": 27,
+		"refresh":       "30s",
+		"time": map[string]string{
+			"from": "now-6h",
+			"to":   "now",
+		},
+		"panels": panels,
+	}
+}
+
+func getPanel(id int, title, kind, expr, legend string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":    id,
+		"title": title,
+		"type":  kind,
+		"gridPos": map[string]int{
+			"h": 8,
+			"w": 12,
+			"x": 12 * ((id - 1) % 2),
+			"y": 8 * ((id - 1) / 2),
+		},
+		"targets": []map[string]string{
+			{
+				"expr":         expr,
+				"legendFormat": legend,
+				"refId":        "A",
+			},
+		},
+	}
+}
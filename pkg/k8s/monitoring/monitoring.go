@@ -0,0 +1,151 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CreateUpdate reconciles the ServiceMonitor, the PrometheusRule and the grafana dashboard of the instance
+func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateUpdate").WithName("Monitoring")
+	monitoring := mongoDB.Spec.Monitoring
+
+	if err := apply(ctx, r, scheme, mongoDB, getServiceMonitor(mongoDB), monitoring.IsServiceMonitorEnabled()); err != nil {
+		log.Error(err, "serviceMonitor processing failed")
+		return err
+	}
+	if err := apply(ctx, r, scheme, mongoDB, getPrometheusRule(mongoDB), monitoring.IsPrometheusRuleEnabled()); err != nil {
+		log.Error(err, "prometheusRule processing failed")
+		return err
+	}
+	if err := createUpdateDashboard(ctx, r, scheme, mongoDB); err != nil {
+		log.Error(err, "grafana dashboard processing failed")
+		return err
+	}
+	return nil
+}
+
+// IsCRDInstalled return whether the kind is served by the API server
+func IsCRDInstalled(r client.Client, gvk schema.GroupVersionKind) bool {
+	_, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
+
+// apply creates or updates the object when enabled and deletes it otherwise.
+// Nothing is done when the kind is not installed on the cluster
+func apply(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB, obj *unstructured.Unstructured, enabled bool) error {
+	gvk := obj.GroupVersionKind()
+	log := util.GetLog(ctx, mongoDB).WithName("Apply").WithValues("kind", gvk.Kind)
+	if !IsCRDInstalled(r, gvk) {
+		log.V(1).Info("kind not installed, skipped")
+		return nil
+	}
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(gvk)
+	err := r.Get(ctx, util.GetTypesNamespaceNamed(ctx, obj), current)
+	if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		log.Error(err, "get failed")
+		return &Error{Cause: err, Detail: "get " + gvk.Kind + " failed"}
+	}
+	found := err == nil
+	if !enabled {
+		if found && metav1.IsControlledBy(current, mongoDB) {
+			log.V(1).Info("delete")
+			if err := r.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "delete failed")
+				return &Error{Cause: err, Detail: "delete " + gvk.Kind + " failed"}
+			}
+		}
+		return nil
+	}
+	if !found {
+		if err := ctrl.SetControllerReference(mongoDB, obj, scheme); err != nil {
+			log.Error(err, "set owner failed")
+			return &Error{Cause: err, Detail: "set owner failed"}
+		}
+		log.V(1).Info("create")
+		if err := r.Create(ctx, obj); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "create failed")
+			return &Error{Cause: err, Detail: "create " + gvk.Kind + " failed"}
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(current, mongoDB) {
+		log.V(1).Info("not owned by the instance, skipped")
+		return nil
+	}
+	if isSame(current, obj) {
+		return nil
+	}
+	current.SetLabels(obj.GetLabels())
+	current.Object["spec"] = obj.Object["spec"]
+	log.V(1).Info("update")
+	if err := r.Update(ctx, current); err != nil {
+		log.Error(err, "update failed")
+		return &Error{Cause: err, Detail: "update " + gvk.Kind + " failed"}
+	}
+	return nil
+}
+
+// isSame return whether the labels and the spec of the object are the ones wanted. The specs are compared
+// in JSON, the numbers read from the API server and the ones built by the operator have distinct types
+func isSame(current, wanted *unstructured.Unstructured) bool {
+	if !reflect.DeepEqual(current.GetLabels(), wanted.GetLabels()) {
+		return false
+	}
+	currentSpec, err := json.Marshal(current.Object["spec"])
+	if err != nil {
+		return false
+	}
+	wantedSpec, err := json.Marshal(wanted.Object["spec"])
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(currentSpec, wantedSpec)
+}
+
+// mergeLabels return the instance labels with the extra ones
+func mergeLabels(name string, extra map[string]string) map[string]string {
+	ls := util.LabelsForMongoDB(name)
+	for k, v := range extra {
+		ls[k] = v
+	}
+	return ls
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Detail
+	}
+	return e.Detail + " : " + e.Cause.Error()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Monitoring", func() {
+	Context("update", func() {
+		var current, wanted *unstructured.Unstructured
+		BeforeEach(func() {
+			wanted = &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"endpoints": []interface{}{map[string]interface{}{"port": "metrics", "interval": "30s"}},
+					"replicas":  3,
+				},
+			}}
+			wanted.SetLabels(map[string]string{"db.w6d.io/release": "test"})
+			// as read from the API server
+			current = &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"endpoints": []interface{}{map[string]interface{}{"interval": "30s", "port": "metrics"}},
+					"replicas":  int64(3),
+				},
+			}}
+			current.SetLabels(map[string]string{"db.w6d.io/release": "test"})
+		})
+		It("skips the object matching the wanted one", func() {
+			Expect(isSame(current, wanted)).To(BeTrue())
+		})
+		It("updates the object whose spec or labels changed", func() {
+			wanted.Object["spec"].(map[string]interface{})["replicas"] = 5
+			Expect(isSame(current, wanted)).To(BeFalse())
+			wanted.Object["spec"].(map[string]interface{})["replicas"] = 3
+			wanted.SetLabels(map[string]string{"db.w6d.io/release": "test", "team": "a"})
+			Expect(isSame(current, wanted)).To(BeFalse())
+		})
+	})
+})
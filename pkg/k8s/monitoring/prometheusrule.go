@@ -0,0 +1,107 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import (
+	"fmt"
+
+	"github.com/w6d-io/mongodb/pkg/k8s/service"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+// GetReplicationLagSeconds return the replication lag above which a member is considered lagging
+func GetReplicationLagSeconds(mongoDB *db.MongoDB) int32 {
+	if m := mongoDB.Spec.Monitoring; m != nil && m.PrometheusRule != nil && m.PrometheusRule.ReplicationLagSeconds != nil {
+		return *m.PrometheusRule.ReplicationLagSeconds
+	}
+	return DefaultReplicationLagSeconds
+}
+
+func getPrometheusRule(mongoDB *db.MongoDB) *unstructured.Unstructured {
+	var extraLabels map[string]string
+	lag := GetReplicationLagSeconds(mongoDB)
+	connections := DefaultConnectionsPercent
+	disk := DefaultDiskUsagePercent
+	if m := mongoDB.Spec.Monitoring; m != nil && m.PrometheusRule != nil {
+		extraLabels = m.PrometheusRule.Labels
+		if m.PrometheusRule.ConnectionsPercent != nil {
+			connections = *m.PrometheusRule.ConnectionsPercent
+		}
+		if m.PrometheusRule.DiskUsagePercent != nil {
+			disk = *m.PrometheusRule.DiskUsagePercent
+		}
+	}
+	selector := fmt.Sprintf(`namespace="%s",service="%s"`, mongoDB.Namespace, service.GetMetricsName(mongoDB))
+	pvcSelector := fmt.Sprintf(`namespace="%s",persistentvolumeclaim=~"datadir-%s-[0-9]+"`, mongoDB.Namespace, mongoDB.Name)
+	rules := []interface{}{
+		getAlert("MongoDBDown",
+			fmt.Sprintf(`mongodb_up{%s} == 0`, selector),
+			"1m", "critical",
+			fmt.Sprintf("MongoDB member {{ $labels.pod }} of %s/%s is down", mongoDB.Namespace, mongoDB.Name)),
+		getAlert("MongoDBReplicaSetMemberUnhealthy",
+			fmt.Sprintf(`mongodb_mongod_replset_member_health{%s} == 0`, selector),
+			"2m", "critical",
+			fmt.Sprintf("member {{ $labels.name }} of %s/%s is unhealthy", mongoDB.Namespace, mongoDB.Name)),
+		getAlert("MongoDBReplicationLag",
+			fmt.Sprintf(`mongodb_mongod_replset_member_replication_lag{%s} > %d`, selector, lag),
+			"5m", "warning",
+			fmt.Sprintf("member {{ $labels.name }} of %s/%s is lagging by {{ $value }}s", mongoDB.Namespace, mongoDB.Name)),
+		getAlert("MongoDBTooManyConnections",
+			fmt.Sprintf(`100 * sum by (pod) (mongodb_connections{%s,state="current"}) / sum by (pod) (mongodb_connections{%s,state=~"current|available"}) > %d`,
+				selector, selector, connections),
+			"5m", "warning",
+			fmt.Sprintf("{{ $labels.pod }} of %s/%s uses {{ $value }}%% of its connections", mongoDB.Namespace, mongoDB.Name)),
+		getAlert("MongoDBDiskUsage",
+			fmt.Sprintf(`100 * kubelet_volume_stats_used_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} > %d`,
+				pvcSelector, pvcSelector, disk),
+			"5m", "warning",
+			fmt.Sprintf("volume {{ $labels.persistentvolumeclaim }} of %s/%s is {{ $value }}%% full", mongoDB.Namespace, mongoDB.Name)),
+	}
+	pr := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name":  fmt.Sprintf("mongodb.%s.%s", mongoDB.Namespace, mongoDB.Name),
+						"rules": rules,
+					},
+				},
+			},
+		},
+	}
+	pr.SetGroupVersionKind(PrometheusRuleGVK)
+	pr.SetName(mongoDB.Name)
+	pr.SetNamespace(mongoDB.Namespace)
+	pr.SetLabels(mergeLabels(mongoDB.Name, extraLabels))
+	return pr
+}
+
+func getAlert(name, expr, duration, severity, summary string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   duration,
+		"labels": map[string]interface{}{
+			"severity": severity,
+		},
+		"annotations": map[string]interface{}{
+			"summary": summary,
+		},
+	}
+}
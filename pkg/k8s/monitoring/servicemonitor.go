@@ -0,0 +1,63 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import (
+	"github.com/w6d-io/mongodb/pkg/k8s/service"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+)
+
+func getServiceMonitor(mongoDB *db.MongoDB) *unstructured.Unstructured {
+	var extraLabels map[string]string
+	endpoint := map[string]interface{}{
+		"port": service.MetricsPortName,
+		"path": "/metrics",
+	}
+	if m := mongoDB.Spec.Monitoring; m != nil && m.ServiceMonitor != nil {
+		extraLabels = m.ServiceMonitor.Labels
+		if m.ServiceMonitor.Interval != "" {
+			endpoint["interval"] = m.ServiceMonitor.Interval
+		}
+		if m.ServiceMonitor.ScrapeTimeout != "" {
+			endpoint["scrapeTimeout"] = m.ServiceMonitor.ScrapeTimeout
+		}
+	}
+	matchLabels := map[string]interface{}{}
+	for k, v := range service.LabelsForMetrics(mongoDB.Name) {
+		matchLabels[k] = v
+	}
+	sm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": matchLabels,
+				},
+				"namespaceSelector": map[string]interface{}{
+					"matchNames": []interface{}{mongoDB.Namespace},
+				},
+				"endpoints": []interface{}{endpoint},
+			},
+		},
+	}
+	sm.SetGroupVersionKind(ServiceMonitorGVK)
+	sm.SetName(mongoDB.Name)
+	sm.SetNamespace(mongoDB.Namespace)
+	sm.SetLabels(mergeLabels(mongoDB.Name, extraLabels))
+	return sm
+}
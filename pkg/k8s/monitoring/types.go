@@ -0,0 +1,46 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package monitoring
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+const (
+	DefaultReplicationLagSeconds int32 = 10
+	DefaultConnectionsPercent    int32 = 80
+	DefaultDiskUsagePercent      int32 = 85
+
+	DashboardKey   string = "mongodb.json"
+	DashboardLabel string = "grafana_dashboard"
+)
+
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "ServiceMonitor",
+	}
+	PrometheusRuleGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PrometheusRule",
+	}
+)
+
+type Error struct {
+	Cause  error
+	Detail string
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package pdb
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CreateUpdate reconciles the PodDisruptionBudget of the data-bearing members.
+// It is removed when it is disabled, when there is a single replica or when the instance is paused
+func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateUpdate").WithName("PodDisruptionBudget")
+	var err error
+
+	current := &policyv1beta1.PodDisruptionBudget{}
+	err = r.Get(ctx, util.GetTypesNamespaceNamed(ctx, mongoDB), current)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "fail to get pod disruption budget")
+		return &Error{Cause: err, Detail: "fail to get pod disruption budget"}
+	}
+	exists := err == nil
+	if !IsEnabled(mongoDB) {
+		if exists && metav1.IsControlledBy(current, mongoDB) {
+			log.V(1).Info("delete pod disruption budget")
+			if err = r.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "fail to delete pod disruption budget")
+				return &Error{Cause: err, Detail: "fail to delete pod disruption budget"}
+			}
+		}
+		return nil
+	}
+	pdb := getPodDisruptionBudget(ctx, scheme, mongoDB)
+	if pdb == nil {
+		log.Error(nil, "get pod disruption budget return nil")
+		return &Error{Cause: nil, Detail: "get pod disruption budget return nil"}
+	}
+	if !exists {
+		log.V(1).Info("create pod disruption budget")
+		if err = r.Create(ctx, pdb); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "fail to create pod disruption budget")
+			return &Error{Cause: err, Detail: "fail to create pod disruption budget"}
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(current, mongoDB) ||
+		(reflect.DeepEqual(current.Spec.MinAvailable, pdb.Spec.MinAvailable) &&
+			reflect.DeepEqual(current.Spec.MaxUnavailable, pdb.Spec.MaxUnavailable)) {
+		return nil
+	}
+	log.V(1).Info("update pod disruption budget")
+	current.Spec.MinAvailable = pdb.Spec.MinAvailable
+	current.Spec.MaxUnavailable = pdb.Spec.MaxUnavailable
+	if err = r.Update(ctx, current); err != nil {
+		log.Error(err, "fail to update pod disruption budget")
+		return &Error{Cause: err, Detail: "fail to update pod disruption budget"}
+	}
+	return nil
+}
+
+// IsEnabled return whether the PodDisruptionBudget has to exist
+func IsEnabled(mongoDB *db.MongoDB) bool {
+	if mongoDB.Spec.Paused {
+		return false
+	}
+	if pdb := mongoDB.Spec.PodDisruptionBudget; pdb != nil && pdb.Enabled != nil && !*pdb.Enabled {
+		return false
+	}
+	return *statefulset.GetReplicas(mongoDB, statefulset.DataMember) > 1
+}
+
+func getPodDisruptionBudget(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB) *policyv1beta1.PodDisruptionBudget {
+	log := util.GetLog(ctx, mongoDB).WithName("GetPodDisruptionBudget")
+	ls := util.LabelsForMongoDB(mongoDB.Name)
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mongoDB.Name,
+			Namespace: mongoDB.Namespace,
+			Labels:    ls,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+		},
+	}
+	spec := mongoDB.Spec.PodDisruptionBudget
+	switch {
+	case spec != nil && spec.MinAvailable != nil:
+		pdb.Spec.MinAvailable = spec.MinAvailable
+	case spec != nil && spec.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = spec.MaxUnavailable
+	default:
+		maxUnavailable := intstr.FromInt(getMaxUnavailable(mongoDB))
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	if err := ctrl.SetControllerReference(mongoDB, pdb, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return pdb
+}
+
+// getMaxUnavailable return 1 when evicting a data-bearing member preserves the majority of the voting members
+func getMaxUnavailable(mongoDB *db.MongoDB) int {
+	voting := int(*statefulset.GetReplicas(mongoDB, statefulset.DataMember))
+	for _, member := range mongoDB.Spec.Members {
+		if member.Votes != nil && *member.Votes == 0 {
+			voting--
+		}
+	}
+	voting += int(*statefulset.GetReplicas(mongoDB, statefulset.ArbiterMember))
+	voting += int(*statefulset.GetReplicas(mongoDB, statefulset.HiddenMember))
+	if voting-(voting/2+1) >= 1 {
+		return 1
+	}
+	return 0
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Detail
+	}
+	return e.Detail + " : " + e.Cause.Error()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package pdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("PodDisruptionBudget", func() {
+	var mongoDB *db.MongoDB
+	var zero, one int32
+	BeforeEach(func() {
+		zero, one = 0, 1
+		replicas := int32(3)
+		mongoDB = &db.MongoDB{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "uid"},
+			Spec:       db.MongoDBSpec{Replicas: &replicas},
+		}
+	})
+	Context("max unavailable", func() {
+		It("allows an eviction preserving the majority of the voting members", func() {
+			Expect(getMaxUnavailable(mongoDB)).To(Equal(1))
+		})
+		It("prevents the eviction breaking the majority", func() {
+			replicas := int32(2)
+			mongoDB.Spec.Replicas = &replicas
+			Expect(getMaxUnavailable(mongoDB)).To(Equal(0))
+			mongoDB.Spec.Arbiter = &db.ArbiterSpec{Replicas: &one}
+			Expect(getMaxUnavailable(mongoDB)).To(Equal(1))
+		})
+		It("does not count the non-voting members", func() {
+			mongoDB.Spec.Members = []db.MemberSpec{{Index: 2, Priority: &zero, Votes: &zero}}
+			Expect(getMaxUnavailable(mongoDB)).To(Equal(0))
+			mongoDB.Spec.Hidden = &db.HiddenSpec{Replicas: &one}
+			Expect(getMaxUnavailable(mongoDB)).To(Equal(1))
+		})
+	})
+	Context("enabled", func() {
+		It("is enabled for several replicas", func() {
+			Expect(IsEnabled(mongoDB)).To(BeTrue())
+			mongoDB.Spec.Replicas = &one
+			Expect(IsEnabled(mongoDB)).To(BeFalse())
+		})
+		It("is disabled by the spec", func() {
+			enabled := false
+			mongoDB.Spec.PodDisruptionBudget = &db.PodDisruptionBudgetSpec{Enabled: &enabled}
+			Expect(IsEnabled(mongoDB)).To(BeFalse())
+		})
+		It("is disabled for the hibernated or paused instances", func() {
+			meta.SetStatusCondition(&mongoDB.Status.Conditions, metav1.Condition{
+				Type: db.ConditionHibernated, Status: metav1.ConditionTrue, Reason: "Hibernated",
+			})
+			Expect(IsEnabled(mongoDB)).To(BeFalse())
+			mongoDB.Status.Conditions = nil
+			mongoDB.Spec.Paused = true
+			Expect(IsEnabled(mongoDB)).To(BeFalse())
+		})
+	})
+	Context("reconcile", func() {
+		var ctx context.Context
+		var scheme *runtime.Scheme
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme = runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(db.AddToScheme(scheme)).To(Succeed())
+		})
+		It("creates the budget then removes it once the instance is paused", func() {
+			r := fake.NewClientBuilder().WithScheme(scheme).Build()
+			Expect(CreateUpdate(ctx, r, scheme, mongoDB)).To(Succeed())
+			current := &policyv1beta1.PodDisruptionBudget{}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(mongoDB), current)).To(Succeed())
+			Expect(current.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+
+			mongoDB.Spec.Paused = true
+			Expect(CreateUpdate(ctx, r, scheme, mongoDB)).To(Succeed())
+			err := r.Get(ctx, client.ObjectKeyFromObject(mongoDB), current)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
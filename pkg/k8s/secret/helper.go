@@ -101,7 +101,9 @@ func getRootSecret(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.Mong
 			Labels:    util.LabelsForMongoDB(mongoDB.Name),
 		},
 		StringData: map[string]string{
-			MongoRootPasswordKey: passwd,
+			MongoRootPasswordKey:    passwd,
+			MongoMetricsPasswordKey: util.GenerateURLSafePassword(30),
+			MongoReplicaSetKey:      util.GenerateURLSafePassword(64),
 		},
 	}
 	if err := ctrl.SetControllerReference(mongoDB, sec, scheme); err != nil {
@@ -110,3 +112,11 @@ func getRootSecret(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.Mong
 	}
 	return sec
 }
+
+// getGeneratedKeys return the keys added to the secrets created before they were introduced
+func getGeneratedKeys() map[string]func() string {
+	return map[string]func() string{
+		MongoMetricsPasswordKey: func() string { return util.GenerateURLSafePassword(30) },
+		MongoReplicaSetKey:      func() string { return util.GenerateURLSafePassword(64) },
+	}
+}
@@ -23,10 +23,12 @@ import (
 
 	"github.com/w6d-io/mongodb/internal/util"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Create(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
@@ -43,6 +45,9 @@ func Create(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoD
 			log.Error(nil, "get secret resource return nil")
 			return &Error{Cause: nil, Detail: "get secret return nil"}
 		}
+		if err = copyAuthSecret(ctx, r, mongoDB, sec); err != nil {
+			return err
+		}
 		err = r.Create(ctx, sec)
 		if err != nil {
 			log.Error(err, "fail to create secret")
@@ -52,6 +57,80 @@ func Create(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoD
 	} else if err != nil {
 		log.Error(err, "fail to get secret")
 		return &Error{Cause: err, Detail: "fail to get secret"}
+	} else if metav1.IsControlledBy(sec, mongoDB) {
+		return addMissingKeys(ctx, r, sec)
+	}
+	return nil
+}
+
+// Orphan removes the instance from the owners of its secret so that it is kept after the deletion
+func Orphan(ctx context.Context, r client.Client, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("Orphan").WithName("Secret")
+	sec := &corev1.Secret{}
+	if err := r.Get(ctx, util.GetTypesNamespaceNamed(ctx, mongoDB), sec); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "fail to get secret")
+		return &Error{Cause: err, Detail: "fail to get secret"}
+	}
+	var owners []metav1.OwnerReference
+	for _, owner := range sec.OwnerReferences {
+		if owner.UID != mongoDB.UID {
+			owners = append(owners, owner)
+		}
+	}
+	if len(owners) == len(sec.OwnerReferences) {
+		return nil
+	}
+	log.V(1).Info("orphan secret")
+	sec.OwnerReferences = owners
+	if err := r.Update(ctx, sec); err != nil {
+		log.Error(err, "fail to update secret")
+		return &Error{Cause: err, Detail: "fail to update secret"}
+	}
+	return nil
+}
+
+// copyAuthSecret overrides the generated credentials with the ones of the auth secret, so that an
+// instance restored from another one keeps the passwords stored in the restored data
+func copyAuthSecret(ctx context.Context, r client.Client, mongoDB *db.MongoDB, sec *corev1.Secret) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CopyAuthSecret")
+	if mongoDB.Spec.AuthSecret == nil || mongoDB.Spec.AuthSecret.Name == mongoDB.Name {
+		return nil
+	}
+	auth := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mongoDB.Spec.AuthSecret.Name, Namespace: mongoDB.Namespace}, auth); err != nil {
+		log.Error(err, "fail to get auth secret")
+		return &Error{Cause: err, Detail: "fail to get auth secret"}
+	}
+	for key, value := range auth.Data {
+		sec.StringData[key] = string(value)
+	}
+	return nil
+}
+
+// addMissingKeys generates the keys missing from a secret created by a previous version
+func addMissingKeys(ctx context.Context, r client.Client, sec *corev1.Secret) error {
+	log := util.GetLog(ctx, sec).WithName("AddMissingKeys")
+	changed := false
+	for key, generate := range getGeneratedKeys() {
+		if _, ok := sec.Data[key]; ok {
+			continue
+		}
+		log.V(1).Info("add key to secret", "key", key)
+		if sec.Data == nil {
+			sec.Data = make(map[string][]byte)
+		}
+		sec.Data[key] = []byte(generate())
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Update(ctx, sec); err != nil {
+		log.Error(err, "fail to update secret")
+		return &Error{Cause: err, Detail: "fail to update secret"}
 	}
 	return nil
 }
@@ -17,7 +17,9 @@ Created on 02/04/2021
 package secret
 
 const (
-	MongoRootPasswordKey string = "mongodb-root-password"
+	MongoRootPasswordKey    string = "mongodb-root-password"
+	MongoMetricsPasswordKey string = "mongodb-metrics-password"
+	MongoReplicaSetKey      string = "mongodb-replica-set-key"
 )
 
 type Error struct {
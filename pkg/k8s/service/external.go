@@ -0,0 +1,245 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// The nodes are cluster-scoped, the marker sits out of the controllers so that the namespaced Role is generated without it
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// CreateUpdateExternal reconciles one service per member when external access is enabled
+// and removes the services that are no longer needed
+func CreateUpdateExternal(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateUpdateExternal").WithName("Service")
+	var err error
+
+	wanted := make(map[string]bool)
+	if mongoDB.Spec.ExternalAccess != nil && mongoDB.Spec.Replicas != nil {
+		for i := 0; i < int(*mongoDB.Spec.Replicas); i++ {
+			svc := getExternalService(ctx, scheme, mongoDB, i)
+			if svc == nil {
+				log.Error(nil, "get external service resource return nil")
+				return &Error{Cause: nil, Detail: "get external service return nil"}
+			}
+			wanted[svc.Name] = true
+			if err = createUpdateExternal(ctx, r, svc); err != nil {
+				return err
+			}
+		}
+	}
+
+	svcs := &corev1.ServiceList{}
+	if err = r.List(ctx, svcs, client.InNamespace(mongoDB.Namespace), client.MatchingLabels(LabelsForExternal(mongoDB.Name))); err != nil {
+		log.Error(err, "fail to list external services")
+		return &Error{Cause: err, Detail: "fail to list external services"}
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if wanted[svc.Name] || !metav1.IsControlledBy(svc, mongoDB) {
+			continue
+		}
+		log.V(1).Info("delete external service", "service", svc.Name)
+		if err = r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "fail to delete external service")
+			return &Error{Cause: err, Detail: "fail to delete external service"}
+		}
+	}
+	return nil
+}
+
+func createUpdateExternal(ctx context.Context, r client.Client, svc *corev1.Service) error {
+	log := util.GetLog(ctx, svc).WithName("CreateUpdateExternal")
+	current := &corev1.Service{}
+	err := r.Get(ctx, util.GetTypesNamespaceNamed(ctx, svc), current)
+	if err != nil && errors.IsNotFound(err) {
+		log.V(1).Info("create external service")
+		if err = r.Create(ctx, svc); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "fail to create external service")
+			return &Error{Cause: err, Detail: "fail to create external service"}
+		}
+		return nil
+	} else if err != nil {
+		log.Error(err, "fail to get external service")
+		return &Error{Cause: err, Detail: "fail to get external service"}
+	}
+	// keep the allocated cluster IP and node port, only the requested fields are updated
+	current.Annotations = svc.Annotations
+	current.Spec.Type = svc.Spec.Type
+	current.Spec.LoadBalancerIP = svc.Spec.LoadBalancerIP
+	if svc.Spec.Ports[0].NodePort != 0 {
+		current.Spec.Ports[0].NodePort = svc.Spec.Ports[0].NodePort
+	}
+	if err = r.Update(ctx, current); err != nil {
+		log.Error(err, "fail to update external service")
+		return &Error{Cause: err, Detail: "fail to update external service"}
+	}
+	return nil
+}
+
+// GetExternalName return the name of the service exposing the member
+func GetExternalName(mongoDB *db.MongoDB, index int) string {
+	return fmt.Sprintf("%s-%d-external", mongoDB.Name, index)
+}
+
+// LabelsForExternal return the labels of the per-member services
+func LabelsForExternal(name string) map[string]string {
+	ls := util.LabelsForMongoDB(name)
+	ls["db.w6d.io/external"] = "true"
+	return ls
+}
+
+func getExternalService(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB, index int) *corev1.Service {
+	log := util.GetLog(ctx, mongoDB).WithName("GetExternalService")
+	ea := mongoDB.Spec.ExternalAccess
+	selector := util.LabelsForMongoDB(mongoDB.Name)
+	selector["statefulset.kubernetes.io/pod-name"] = fmt.Sprintf("%s-%d", mongoDB.Name, index)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GetExternalName(mongoDB, index),
+			Namespace:   mongoDB.Namespace,
+			Labels:      LabelsForExternal(mongoDB.Name),
+			Annotations: ea.Annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "mongodb",
+					Protocol:   "TCP",
+					Port:       db.MongoDBPort,
+					TargetPort: intstr.FromInt(db.MongoDBPort),
+				},
+			},
+			Selector:                 selector,
+			Type:                     ea.Type,
+			PublishNotReadyAddresses: true,
+		},
+	}
+	if index < len(ea.LoadBalancerIPs) {
+		svc.Spec.LoadBalancerIP = ea.LoadBalancerIPs[index]
+	}
+	if index < len(ea.NodePorts) {
+		svc.Spec.Ports[0].NodePort = ea.NodePorts[index]
+	}
+	if err := ctrl.SetControllerReference(mongoDB, svc, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return svc
+}
+
+// GetExternalEndpoints return the external address of each member.
+// The address is empty while the load balancer or the pod is not scheduled
+func GetExternalEndpoints(ctx context.Context, r client.Client, mongoDB *db.MongoDB) ([]db.ExternalEndpoint, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetExternalEndpoints")
+	var endpoints []db.ExternalEndpoint
+	ea := mongoDB.Spec.ExternalAccess
+	if ea == nil || mongoDB.Spec.Replicas == nil {
+		return endpoints, nil
+	}
+	for i := 0; i < int(*mongoDB.Spec.Replicas); i++ {
+		endpoint := db.ExternalEndpoint{Pod: fmt.Sprintf("%s-%d", mongoDB.Name, i)}
+		svc := &corev1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: GetExternalName(mongoDB, i), Namespace: mongoDB.Namespace}, svc)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "fail to get external service")
+			return nil, err
+		}
+		if err == nil {
+			host, port, err := getExternalHostPort(ctx, r, mongoDB, svc, endpoint.Pod)
+			if err != nil {
+				return nil, err
+			}
+			if i < len(ea.Hosts) && ea.Hosts[i] != "" {
+				host = ea.Hosts[i]
+			}
+			if _, _, err := net.SplitHostPort(host); err == nil {
+				endpoint.Address = host
+			} else if host != "" && port != 0 {
+				endpoint.Address = net.JoinHostPort(host, strconv.Itoa(int(port)))
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+func getExternalHostPort(ctx context.Context, r client.Client, mongoDB *db.MongoDB, svc *corev1.Service, podName string) (string, int32, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("GetExternalHostPort")
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return ingress.IP, db.MongoDBPort, nil
+			}
+			if ingress.Hostname != "" {
+				return ingress.Hostname, db.MongoDBPort, nil
+			}
+		}
+		return "", db.MongoDBPort, nil
+	case corev1.ServiceTypeNodePort:
+		port := svc.Spec.Ports[0].NodePort
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: mongoDB.Namespace}, pod); err != nil {
+			if errors.IsNotFound(err) {
+				return "", port, nil
+			}
+			log.Error(err, "fail to get pod")
+			return "", port, err
+		}
+		if pod.Spec.NodeName == "" {
+			return "", port, nil
+		}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			log.Error(err, "fail to get node")
+			return "", port, err
+		}
+		return getNodeAddress(node), port, nil
+	}
+	return "", 0, nil
+}
+
+// getNodeAddress return the external IP of the node or its internal IP
+func getNodeAddress(node *corev1.Node) string {
+	var internal string
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeExternalIP:
+			return address.Address
+		case corev1.NodeInternalIP:
+			internal = address.Address
+		}
+	}
+	return internal
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package service
+
+import (
+	"context"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CreateDeleteHeadless creates the headless service of each kind of member requested
+// and deletes the ones no longer needed
+func CreateDeleteHeadless(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateDeleteHeadless").WithName("Service")
+	members := statefulset.GetMembers(mongoDB)
+	for _, member := range statefulset.Members {
+		svc := &corev1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: statefulset.GetHeadlessName(mongoDB, member), Namespace: mongoDB.Namespace}, svc)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "fail to get headless service")
+			return &Error{Cause: err, Detail: "fail to get headless service"}
+		}
+		if !util.StringInArray(member, members) {
+			if err == nil && metav1.IsControlledBy(svc, mongoDB) {
+				log.V(1).Info("delete headless service", "service", svc.Name)
+				if err = r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+					log.Error(err, "fail to delete headless service")
+					return &Error{Cause: err, Detail: "fail to delete headless service"}
+				}
+			}
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		svc = getHeadlessService(ctx, scheme, mongoDB, member)
+		if svc == nil {
+			log.Error(nil, "get headless service resource return nil")
+			return &Error{Cause: nil, Detail: "get headless service return nil"}
+		}
+		log.V(1).Info("create headless service", "service", svc.Name)
+		if err = r.Create(ctx, svc); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "fail to create headless service")
+			return &Error{Cause: err, Detail: "fail to create headless service"}
+		}
+	}
+	return nil
+}
+
+func getHeadlessService(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB, member string) *corev1.Service {
+	log := util.GetLog(ctx, mongoDB).WithName("GetHeadlessService")
+	ls := statefulset.GetLabels(mongoDB, member)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      statefulset.GetHeadlessName(mongoDB, member),
+			Namespace: mongoDB.Namespace,
+			Labels:    ls,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "mongodb",
+					Protocol:   "TCP",
+					Port:       db.MongoDBPort,
+					TargetPort: intstr.FromInt(db.MongoDBPort),
+				},
+			},
+			Selector:                 ls,
+			ClusterIP:                corev1.ClusterIPNone,
+			Type:                     corev1.ServiceTypeClusterIP,
+			PublishNotReadyAddresses: true,
+		},
+	}
+	if err := ctrl.SetControllerReference(mongoDB, svc, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return svc
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package service
+
+import (
+	"context"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CreateRoles creates the services selecting the primary and the secondaries from the pod role label
+func CreateRoles(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateRoles").WithName("Service")
+	for _, role := range []string{statefulset.RolePrimary, statefulset.RoleSecondary} {
+		svc := &corev1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: GetRoleName(mongoDB, role), Namespace: mongoDB.Namespace}, svc)
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			log.Error(err, "fail to get role service", "role", role)
+			return &Error{Cause: err, Detail: "fail to get role service"}
+		}
+		svc = getRoleService(ctx, scheme, mongoDB, role)
+		if svc == nil {
+			log.Error(nil, "get role service resource return nil")
+			return &Error{Cause: nil, Detail: "get role service return nil"}
+		}
+		log.V(1).Info("create role service", "service", svc.Name)
+		if err = r.Create(ctx, svc); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "fail to create role service")
+			return &Error{Cause: err, Detail: "fail to create role service"}
+		}
+	}
+	return nil
+}
+
+// GetRoleName return the name of the service of the role, `<name>-primary` or `<name>-readonly`
+func GetRoleName(mongoDB *db.MongoDB, role string) string {
+	if role == statefulset.RoleSecondary {
+		return mongoDB.Name + "-readonly"
+	}
+	return mongoDB.Name + "-" + role
+}
+
+func getRoleService(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB, role string) *corev1.Service {
+	log := util.GetLog(ctx, mongoDB).WithName("GetRoleService")
+	selector := util.LabelsForMongoDB(mongoDB.Name)
+	selector[statefulset.RoleLabel] = role
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetRoleName(mongoDB, role),
+			Namespace: mongoDB.Namespace,
+			Labels:    util.LabelsForMongoDB(mongoDB.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "mongodb",
+					Protocol:   "TCP",
+					Port:       db.MongoDBPort,
+					TargetPort: intstr.FromInt(db.MongoDBPort),
+				},
+			},
+			Selector: selector,
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+	if err := ctrl.SetControllerReference(mongoDB, svc, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return svc
+}
@@ -20,8 +20,10 @@ import (
 	"context"
 
 	"github.com/w6d-io/mongodb/internal/util"
+	"github.com/w6d-io/mongodb/pkg/k8s/statefulset"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -36,6 +38,10 @@ type Error struct {
 	Detail string
 }
 
+const (
+	MetricsPortName string = "metrics"
+)
+
 func Create(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
 	log := util.GetLog(ctx, mongoDB).WithName("Create").WithName("Service")
 	var err error
@@ -89,6 +95,83 @@ func getService(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB
 	return svc
 }
 
+// CreateDeleteMetrics creates the metrics service when monitoring is enabled or deletes it otherwise
+func CreateDeleteMetrics(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
+	log := util.GetLog(ctx, mongoDB).WithName("CreateDeleteMetrics").WithName("Service")
+	var err error
+
+	svc := &corev1.Service{}
+	err = r.Get(ctx, types.NamespacedName{Name: GetMetricsName(mongoDB), Namespace: mongoDB.Namespace}, svc)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "fail to get metrics service")
+		return &Error{Cause: err, Detail: "fail to get metrics service"}
+	}
+	if !mongoDB.Spec.Monitoring.IsEnabled() {
+		if err == nil && metav1.IsControlledBy(svc, mongoDB) {
+			log.V(1).Info("delete metrics service")
+			if err = r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "fail to delete metrics service")
+				return &Error{Cause: err, Detail: "fail to delete metrics service"}
+			}
+		}
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	log.V(1).Info("create metrics service")
+	svc = getMetricsService(ctx, scheme, mongoDB)
+	if svc == nil {
+		log.Error(nil, "get metrics service resource return nil")
+		return &Error{Cause: nil, Detail: "get metrics service return nil"}
+	}
+	if err = r.Create(ctx, svc); err != nil && !errors.IsAlreadyExists(err) {
+		log.Error(err, "fail to create metrics service")
+		return &Error{Cause: err, Detail: "fail to create metrics service"}
+	}
+	return nil
+}
+
+// GetMetricsName return the name of the metrics service
+func GetMetricsName(mongoDB *db.MongoDB) string {
+	return mongoDB.Name + "-metrics"
+}
+
+// LabelsForMetrics return the labels of the metrics service
+func LabelsForMetrics(name string) map[string]string {
+	ls := util.LabelsForMongoDB(name)
+	ls["db.w6d.io/metrics"] = "true"
+	return ls
+}
+
+func getMetricsService(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB) *corev1.Service {
+	log := util.GetLog(ctx, mongoDB).WithName("GetMetricsService")
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetMetricsName(mongoDB),
+			Namespace: mongoDB.Namespace,
+			Labels:    LabelsForMetrics(mongoDB.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       MetricsPortName,
+					Protocol:   "TCP",
+					Port:       statefulset.MongoContainerMetricsPort,
+					TargetPort: intstr.FromInt(int(statefulset.MongoContainerMetricsPort)),
+				},
+			},
+			Selector: util.LabelsForMongoDB(mongoDB.Name),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+	if err := ctrl.SetControllerReference(mongoDB, svc, scheme); err != nil {
+		log.Error(err, "set owner failed")
+		return nil
+	}
+	return svc
+}
+
 func (e *Error) Error() string {
 	if e.Cause == nil {
 		return e.Detail
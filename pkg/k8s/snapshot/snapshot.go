@@ -0,0 +1,95 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package snapshot
+
+import (
+	"context"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Create creates the snapshot when it does not exist
+func Create(ctx context.Context, r client.Client, vs *unstructured.Unstructured) error {
+	log := util.GetLog(ctx, vs).WithName("Create").WithName("VolumeSnapshot")
+	if _, err := r.RESTMapper().RESTMapping(VolumeSnapshotGVK.GroupKind(), VolumeSnapshotGVK.Version); err != nil {
+		log.Error(err, "VolumeSnapshot kind is not installed")
+		return &Error{Cause: err, Detail: "VolumeSnapshot kind is not installed"}
+	}
+	claimName, _, _ := unstructured.NestedString(vs.Object, "spec", "source", "persistentVolumeClaimName")
+	log.V(1).Info("create snapshot", "pvc", claimName)
+	if err := r.Create(ctx, vs); err != nil && !errors.IsAlreadyExists(err) {
+		log.Error(err, "create snapshot failed")
+		return &Error{Cause: err, Detail: "create snapshot failed"}
+	}
+	return nil
+}
+
+// IsReady return whether the snapshot can be used to provision a volume
+func IsReady(ctx context.Context, r client.Client, namespace, name string) (bool, error) {
+	status, err := GetStatus(ctx, r, namespace, name)
+	if err != nil {
+		return false, err
+	}
+	return status.ReadyToUse, nil
+}
+
+// GetStatus return the status of the snapshot
+func GetStatus(ctx context.Context, r client.Client, namespace, name string) (*Status, error) {
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(VolumeSnapshotGVK)
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, vs); err != nil {
+		return nil, &Error{Cause: err, Detail: "get snapshot failed"}
+	}
+	status := &Status{}
+	status.ReadyToUse, _, _ = unstructured.NestedBool(vs.Object, "status", "readyToUse")
+	status.CreationTime, _, _ = unstructured.NestedString(vs.Object, "status", "creationTime")
+	status.Error, _, _ = unstructured.NestedString(vs.Object, "status", "error", "message")
+	return status, nil
+}
+
+// GetVolumeSnapshot return the snapshot of the persistent volume claim
+func GetVolumeSnapshot(namespace, name, claimName, className string, labels map[string]string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": claimName,
+		},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	vs := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	vs.SetGroupVersionKind(VolumeSnapshotGVK)
+	vs.SetName(name)
+	vs.SetNamespace(namespace)
+	vs.SetLabels(labels)
+	return vs
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Detail
+	}
+	return e.Detail + " : " + e.Cause.Error()
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package snapshot
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+const (
+	// SnapshotLabel holds the kind of snapshot
+	SnapshotLabel string = "db.w6d.io/snapshot"
+	// FinalSnapshot is the kind of the snapshots taken before the instance is deleted
+	FinalSnapshot string = "final"
+	// BackupSnapshot is the kind of the snapshots taken by a backup
+	BackupSnapshot string = "backup"
+)
+
+var (
+	VolumeSnapshotGVK = schema.GroupVersionKind{
+		Group:   "snapshot.storage.k8s.io",
+		Version: "v1",
+		Kind:    "VolumeSnapshot",
+	}
+)
+
+// Status is the observed state of a snapshot
+type Status struct {
+	// ReadyToUse is true once the snapshot can be used to provision a volume
+	ReadyToUse bool
+	// CreationTime is set once the snapshot is cut, the source can be written again
+	CreationTime string
+	// Error is the last error of the snapshot
+	Error string
+}
+
+type Error struct {
+	Cause  error
+	Detail string
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/w6d-io/mongodb/internal/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ExpandVolumes grows the volumes of the members when the requested storage is larger than the
+// statefulSet claim template. Each claim is patched, then once the file systems are resized the
+// statefulSet is deleted without its pods, to be recreated with the new claim template.
+// It returns a message describing the step in progress, empty when there is nothing to do
+func ExpandVolumes(ctx context.Context, r client.Client, mongoDB *db.MongoDB) (string, error) {
+	for _, member := range GetMembers(mongoDB) {
+		if member == ArbiterMember {
+			continue
+		}
+		message, err := expandVolumes(ctx, r, mongoDB, member)
+		if err != nil || message != "" {
+			return message, err
+		}
+	}
+	return "", nil
+}
+
+func expandVolumes(ctx context.Context, r client.Client, mongoDB *db.MongoDB, member string) (string, error) {
+	log := util.GetLog(ctx, mongoDB).WithName("ExpandVolumes").WithValues("member", member)
+	sts := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: GetName(mongoDB, member), Namespace: mongoDB.Namespace}, sts)
+	if errors.IsNotFound(err) || (err == nil && sts.DeletionTimestamp != nil) {
+		return "", nil
+	}
+	if err != nil {
+		log.Error(err, "get statefulSet failed")
+		return "", &Error{Cause: err, Detail: "get statefulSet failed"}
+	}
+	claims := getVolumeClaimTemplates(mongoDB, member)
+	if len(claims) == 0 || len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return "", nil
+	}
+	wanted, ok := claims[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return "", nil
+	}
+	current := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	if wanted.Cmp(current) <= 0 {
+		return "", nil
+	}
+
+	resizing := 0
+	for i := 0; i < int(*sts.Spec.Replicas); i++ {
+		pvc := &corev1.PersistentVolumeClaim{}
+		name := fmt.Sprintf("%s-%s-%d", DataVolumeName, sts.Name, i)
+		if err = r.Get(ctx, types.NamespacedName{Name: name, Namespace: mongoDB.Namespace}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			log.Error(err, "get persistent volume claim failed", "pvc", name)
+			return "", &Error{Cause: err, Detail: "get persistent volume claim failed"}
+		}
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if requested.Cmp(wanted) < 0 {
+			log.V(1).Info("expand persistent volume claim", "pvc", name, "size", wanted.String())
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = wanted
+			if err = r.Patch(ctx, pvc, patch); err != nil {
+				log.Error(err, "patch persistent volume claim failed", "pvc", name)
+				return "", &Error{Cause: err, Detail: "patch persistent volume claim failed"}
+			}
+			resizing++
+			continue
+		}
+		if !isResized(pvc, wanted) {
+			resizing++
+		}
+	}
+	if resizing > 0 {
+		return fmt.Sprintf("waiting for %d volumes of %s to be resized to %s", resizing, sts.Name, wanted.String()), nil
+	}
+
+	log.V(1).Info("delete statefulSet orphaning its pods to update the claim template")
+	if err = r.Delete(ctx, sts, client.PropagationPolicy("Orphan")); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "delete statefulSet failed")
+		return "", &Error{Cause: err, Detail: "delete statefulSet failed"}
+	}
+	return fmt.Sprintf("recreating %s with the %s claim template", sts.Name, wanted.String()), nil
+}
+
+// isResized return whether the capacity of the claim reached the size and the file system was resized
+func isResized(pvc *corev1.PersistentVolumeClaim, size resource.Quantity) bool {
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(size) < 0 {
+		return false
+	}
+	for _, condition := range pvc.Status.Conditions {
+		if (condition.Type == corev1.PersistentVolumeClaimResizing ||
+			condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending) &&
+			condition.Status == corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Expansion", func() {
+	var ctx context.Context
+	var mongoDB *db.MongoDB
+	var sts *appsv1.StatefulSet
+	var pvc *corev1.PersistentVolumeClaim
+	storage := func(size string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+	}
+	BeforeEach(func() {
+		ctx = context.Background()
+		mongoDB = &db.MongoDB{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: db.MongoDBSpec{
+				Storage: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.ResourceRequirements{Requests: storage("2Gi")},
+				},
+			},
+		}
+		replicas := int32(1)
+		sts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: DataVolumeName},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							Resources: corev1.ResourceRequirements{Requests: storage("1Gi")},
+						},
+					},
+				},
+			},
+		}
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: DataVolumeName + "-test-0", Namespace: "default"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{Requests: storage("1Gi")},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Capacity: storage("1Gi")},
+		}
+	})
+	Context("volumes", func() {
+		It("does nothing when the claim template has the requested storage", func() {
+			mongoDB.Spec.Storage.Resources.Requests = storage("1Gi")
+			r := fake.NewClientBuilder().WithObjects(sts, pvc).Build()
+			Expect(ExpandVolumes(ctx, r, mongoDB)).To(BeEmpty())
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(sts), &appsv1.StatefulSet{})).To(Succeed())
+		})
+		It("does nothing when the statefulSet does not exist", func() {
+			r := fake.NewClientBuilder().Build()
+			Expect(ExpandVolumes(ctx, r, mongoDB)).To(BeEmpty())
+		})
+		It("patches the claims and waits for the resize", func() {
+			r := fake.NewClientBuilder().WithObjects(sts, pvc).Build()
+			Expect(ExpandVolumes(ctx, r, mongoDB)).To(ContainSubstring("waiting for 1 volumes"))
+			updated := &corev1.PersistentVolumeClaim{}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(pvc), updated)).To(Succeed())
+			Expect(updated.Spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, resource.MustParse("2Gi")))
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(sts), &appsv1.StatefulSet{})).To(Succeed())
+		})
+		It("waits for the file system of the patched claims to be resized", func() {
+			pvc.Spec.Resources.Requests = storage("2Gi")
+			pvc.Status.Capacity = storage("2Gi")
+			pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+				{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+			}
+			r := fake.NewClientBuilder().WithObjects(sts, pvc).Build()
+			Expect(ExpandVolumes(ctx, r, mongoDB)).To(ContainSubstring("waiting for 1 volumes"))
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(sts), &appsv1.StatefulSet{})).To(Succeed())
+		})
+		It("deletes the statefulSet once the claims are resized", func() {
+			pvc.Spec.Resources.Requests = storage("2Gi")
+			pvc.Status.Capacity = storage("2Gi")
+			r := fake.NewClientBuilder().WithObjects(sts, pvc).Build()
+			Expect(ExpandVolumes(ctx, r, mongoDB)).To(ContainSubstring("recreating test"))
+			err := r.Get(ctx, types.NamespacedName{Name: "test", Namespace: "default"}, &appsv1.StatefulSet{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+	Context("resize", func() {
+		It("handles the capacity and the resizing conditions", func() {
+			size := resource.MustParse("2Gi")
+			Expect(isResized(pvc, size)).To(BeFalse())
+			pvc.Status.Capacity = storage("2Gi")
+			Expect(isResized(pvc, size)).To(BeTrue())
+			pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+				{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionTrue},
+			}
+			Expect(isResized(pvc, size)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/w6d-io/mongodb/internal/config"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// flavor builds the parts of the mongodb container depending on the distribution of the image
+type flavor interface {
+	// getImage return the image of a version missing from the catalog
+	getImage(version string) string
+	// getDataPath return the mount path of the data volume
+	getDataPath() string
+	// getEnv return the environment of the mongodb container
+	getEnv(mongoDB *db.MongoDB, member string) []corev1.EnvVar
+	// getVolumeMounts return the scripts and the writable paths mounted in the mongodb container
+	getVolumeMounts(mongoDB *db.MongoDB, member string) []corev1.VolumeMount
+	// getVolumes return the volumes needed by the flavor
+	getVolumes(mongoDB *db.MongoDB) []corev1.Volume
+}
+
+// getFlavor return the flavor of the image of the instance
+func getFlavor(mongoDB *db.MongoDB) flavor {
+	switch mongoDB.Spec.GetFlavor() {
+	case db.FlavorOfficial:
+		return official{}
+	case db.FlavorPercona:
+		return percona{}
+	}
+	return bitnami{}
+}
+
+// getImageOrDefault return the tag of the version of the image from the configuration or the default image
+func getImageOrDefault(key, image, version string) string {
+	if configured := config.GetImage(key); configured != "" {
+		image = configured
+	} else {
+		image = config.GetMirror(image)
+	}
+	return fmt.Sprintf("%s:%s", image, version)
+}
+
+// getSecretEnv return the variable holding the key of the instance secret
+func getSecretEnv(mongoDB *db.MongoDB, name, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: mongoDB.Name,
+				},
+				Key: key,
+			},
+		},
+	}
+}
+
+// getShellEnv return the variables of the shell run by the setup scripts
+func getShellEnv(mongoDB *db.MongoDB) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "MONGODB_SHELL",
+			Value: GetShell(mongoDB),
+		},
+		{
+			Name:  "MONGODB_SHELL_FLAGS",
+			Value: strings.Join(getShellFlags(mongoDB), " "),
+		},
+	}
+}
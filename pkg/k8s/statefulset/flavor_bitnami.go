@@ -0,0 +1,163 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"fmt"
+
+	"github.com/w6d-io/mongodb/internal/config"
+	"github.com/w6d-io/mongodb/pkg/k8s/secret"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// bitnami runs the bitnami/mongodb image, configured from the environment by the entrypoint of the image
+type bitnami struct{}
+
+func (bitnami) getImage(version string) string {
+	return fmt.Sprintf("%s:%s-debian-10", config.GetImage(MongoName), version)
+}
+
+func (bitnami) getDataPath() string {
+	return "/bitnami/mongodb"
+}
+
+// getVolumeMounts return the setup script and the writable paths needed by the bitnami image
+// when the root filesystem is read-only
+func (bitnami) getVolumeMounts(_ *db.MongoDB, member string) []corev1.VolumeMount {
+	script := getScriptKey(member)
+	return []corev1.VolumeMount{
+		{
+			Name:      ScriptsVolumeName,
+			MountPath: "/scripts/" + script,
+			SubPath:   script,
+		},
+		{
+			Name:      EmptyDirVolumeName,
+			MountPath: "/tmp",
+			SubPath:   "tmp-dir",
+		},
+		{
+			Name:      EmptyDirVolumeName,
+			MountPath: "/opt/bitnami/mongodb/conf",
+			SubPath:   "app-conf-dir",
+		},
+		{
+			Name:      EmptyDirVolumeName,
+			MountPath: "/opt/bitnami/mongodb/tmp",
+			SubPath:   "app-tmp-dir",
+		},
+		{
+			Name:      EmptyDirVolumeName,
+			MountPath: "/opt/bitnami/mongodb/logs",
+			SubPath:   "app-logs-dir",
+		},
+	}
+}
+
+func (bitnami) getVolumes(*db.MongoDB) []corev1.Volume {
+	return nil
+}
+
+// getEnv return the variables configuring the entrypoint of the image
+func (bitnami) getEnv(mongoDB *db.MongoDB, member string) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  "BITNAMI_DEBUG",
+			Value: "false",
+		},
+		{
+			Name: "MY_POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name:  "K8S_SERVICE_NAME",
+			Value: GetHeadlessName(mongoDB, member),
+		},
+		{
+			Name:  "MONGODB_ADVERTISED_HOSTNAME",
+			Value: fmt.Sprintf("$(MY_POD_NAME).%s.%s.svc.cluster.local", GetHeadlessName(mongoDB, member), mongoDB.Namespace),
+		},
+		{
+			Name:  "MONGODB_PORT_NUMBER",
+			Value: fmt.Sprintf("%d", MongoContainerPort),
+		},
+		{
+			Name:  "MONGODB_INITIAL_PRIMARY_HOST",
+			Value: getFullname(mongoDB),
+		},
+		{
+			Name:  "MONGODB_REPLICA_SET_NAME",
+			Value: db.MongoDBReplicaSetName,
+		},
+		{
+			Name: "MONGODB_REPLICA_SET_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: mongoDB.Name,
+					},
+					Key: secret.MongoReplicaSetKey,
+				},
+			},
+		},
+		{
+			Name: "MONGODB_ROOT_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: mongoDB.Name,
+					},
+					Key: MongoRootPasswordKey,
+				},
+			},
+		},
+		{
+			Name:  "ALLOW_EMPTY_PASSWORD",
+			Value: "no",
+		},
+		{
+			Name:  "MONGODB_SYSTEM_LOG_VERBOSITY",
+			Value: "0",
+		},
+		{
+			Name:  "MONGODB_DISABLE_SYSTEM_LOG",
+			Value: "no",
+		},
+		{
+			Name:  "MONGODB_DISABLE_JAVASCRIPT",
+			Value: "no",
+		},
+		{
+			Name:  "MONGODB_ENABLE_IPV6",
+			Value: "no",
+		},
+		{
+			Name:  "MONGODB_ENABLE_DIRECTORY_PER_DB",
+			Value: "no",
+		},
+	}
+	if tls := AddEnvTLS(mongoDB.Spec.TLS); len(tls) > 0 {
+		env = append(env, tls...)
+	}
+	return env
+}
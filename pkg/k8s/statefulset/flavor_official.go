@@ -0,0 +1,83 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"fmt"
+
+	"github.com/w6d-io/mongodb/pkg/k8s/configmap"
+	"github.com/w6d-io/mongodb/pkg/k8s/secret"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// official runs the official mongo image. The setup script renders no configuration, mongod reads
+// the configuration file of the scripts configmap and the members are joined by the operator
+type official struct{}
+
+func (official) getImage(version string) string {
+	return getImageOrDefault(OfficialImageKey, OfficialImage, version)
+}
+
+func (official) getDataPath() string {
+	return configmap.DataPath
+}
+
+// getEnv return the variables read by the setup script
+func (official) getEnv(mongoDB *db.MongoDB, member string) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name: "MY_POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name:  "MONGODB_ADVERTISED_HOSTNAME",
+			Value: fmt.Sprintf("$(MY_POD_NAME).%s.%s.svc.cluster.local", GetHeadlessName(mongoDB, member), mongoDB.Namespace),
+		},
+		{
+			Name:  "MONGODB_PORT_NUMBER",
+			Value: fmt.Sprintf("%d", MongoContainerPort),
+		},
+		getSecretEnv(mongoDB, "MONGODB_REPLICA_SET_KEY", secret.MongoReplicaSetKey),
+		getSecretEnv(mongoDB, "MONGODB_ROOT_PASSWORD", MongoRootPasswordKey),
+	}
+	return append(env, getShellEnv(mongoDB)...)
+}
+
+// getVolumeMounts return the scripts and the configuration, mongod only writes the data and /tmp
+func (official) getVolumeMounts(*db.MongoDB, string) []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      ScriptsVolumeName,
+			MountPath: "/scripts",
+		},
+		{
+			Name:      EmptyDirVolumeName,
+			MountPath: "/tmp",
+			SubPath:   "tmp-dir",
+		},
+	}
+}
+
+func (official) getVolumes(*db.MongoDB) []corev1.Volume {
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// percona runs the Percona Server for MongoDB image, set up like the official image.
+// The master key of the encryption at rest is mounted from its secret
+type percona struct {
+	official
+}
+
+func (percona) getImage(version string) string {
+	return getImageOrDefault(PerconaImageKey, PerconaImage, version)
+}
+
+// getEnv return the variables read by the setup script, the key file is copied by the script
+// since mongod refuses a key readable by the group set by the fsGroup of the pod
+func (p percona) getEnv(mongoDB *db.MongoDB, member string) []corev1.EnvVar {
+	env := p.official.getEnv(mongoDB, member)
+	if getEncryption(mongoDB) != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "MONGODB_ENCRYPTION_KEY_FILE",
+			Value: EncryptionKeyPath + "/" + EncryptionKeyName,
+		})
+	}
+	return env
+}
+
+func (p percona) getVolumeMounts(mongoDB *db.MongoDB, member string) []corev1.VolumeMount {
+	vm := p.official.getVolumeMounts(mongoDB, member)
+	if getEncryption(mongoDB) == nil {
+		return vm
+	}
+	return append(vm, corev1.VolumeMount{
+		Name:      EncryptionKeyVolumeName,
+		MountPath: EncryptionKeyPath,
+		ReadOnly:  true,
+	})
+}
+
+// getVolumes return the volume of the master key when the data is encrypted
+func (percona) getVolumes(mongoDB *db.MongoDB) []corev1.Volume {
+	var mode int32 = 0400
+	encryption := getEncryption(mongoDB)
+	if encryption == nil {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: EncryptionKeyVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: encryption.KeySecret.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  encryption.KeySecret.Key,
+							Path: EncryptionKeyName,
+							Mode: &mode,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getEncryption return the encryption at rest of the instance
+func getEncryption(mongoDB *db.MongoDB) *db.EncryptionSpec {
+	if mongoDB.Spec.Percona == nil {
+		return nil
+	}
+	return mongoDB.Spec.Percona.Encryption
+}
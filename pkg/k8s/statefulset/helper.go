@@ -18,35 +18,39 @@ package statefulset
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/w6d-io/mongodb/internal/config"
 	"github.com/w6d-io/mongodb/internal/util"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"github.com/w6d-io/mongodb/pkg/k8s/configmap"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	k8sv1alpha1 "github.com/w6d-io/mongodb/apis/k8s/v1alpha1"
 	k8sdbv1alpha1 "github.com/w6d-io/mongodb/apis/k8sdb/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func getStatefulSetMongoDB(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) *appsv1.StatefulSet {
-	log := util.GetLog(ctx, mongoDB)
-	ls := util.LabelsForMongoDB(mongoDB.Name)
-	var fsGroup int64 = 1001
+func getStatefulSetMongoDB(ctx context.Context, scheme *runtime.Scheme, mongoDB *db.MongoDB, member string) *appsv1.StatefulSet {
+	log := util.GetLog(ctx, mongoDB).WithValues("member", member)
+	ls := GetLabels(mongoDB, member)
 	log.V(1).Info("build statefulSet")
 	sts := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      mongoDB.Name,
+			Name:      GetName(mongoDB, member),
 			Namespace: mongoDB.Namespace,
 			Labels:    ls,
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas: mongoDB.Spec.Replicas,
+			Replicas: GetReplicas(mongoDB, member),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: ls,
 			},
@@ -54,36 +58,24 @@ func getStatefulSetMongoDB(ctx context.Context, r client.Client, scheme *runtime
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: ls,
 					Annotations: map[string]string{
-						"checksum/configuration": util.AsSha256(mongoDB),
+						ChecksumAnnotation: getChecksum(mongoDB, member),
 					},
 				},
 				Spec: corev1.PodSpec{
-					InitContainers: getInitContainers(mongoDB),
-					Containers: []corev1.Container{
-						getContainers(ctx, mongoDB),
-						getMetricsContainers(ctx, r, mongoDB),
-					},
+					InitContainers:     getInitContainers(mongoDB),
+					Containers:         getPodContainers(ctx, mongoDB, member),
 					NodeSelector:       util.GetNodeSelector(mongoDB.Spec.PodTemplate),
 					ServiceAccountName: util.GetServiceAccount(mongoDB.Spec.PodTemplate),
-					//SecurityContext:    util.GetSecurityContext(mongoDB.Spec.PodTemplate),
-					SecurityContext: &corev1.PodSecurityContext{
-						FSGroup: &fsGroup,
-					},
-					Affinity:    util.GetAffinity(mongoDB.Spec.PodTemplate),
-					Tolerations: util.GetTolerations(mongoDB.Spec.PodTemplate),
-					Volumes:     AddVolumeTLS(mongoDB.Spec.TLS),
+					SecurityContext:    util.GetSecurityContext(mongoDB.Spec.PodTemplate),
+					Affinity:           util.GetAffinity(mongoDB.Spec.PodTemplate),
+					Tolerations:        util.GetTolerations(mongoDB.Spec.PodTemplate),
+					Volumes:            getVolumes(mongoDB, member),
+					ImagePullSecrets:   config.GetImagePullSecrets(mongoDB.Spec.Version),
 				},
 			},
-			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "datadir",
-					},
-					Spec: mongoDB.Spec.Storage,
-				},
-			},
-			ServiceName:         mongoDB.Name,
-			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+			VolumeClaimTemplates: getVolumeClaimTemplates(mongoDB, member),
+			ServiceName:          GetHeadlessName(mongoDB, member),
+			PodManagementPolicy:  appsv1.OrderedReadyPodManagement,
 			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
 				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
 				RollingUpdate: nil,
@@ -98,133 +90,243 @@ func getStatefulSetMongoDB(ctx context.Context, r client.Client, scheme *runtime
 	return sts
 }
 
-func getContainers(ctx context.Context, mongoDB *db.MongoDB) corev1.Container {
+// GetMembers return the kinds of member requested by the spec, the data-bearing members are always requested
+func GetMembers(mongoDB *db.MongoDB) []string {
+	members := []string{DataMember}
+	if mongoDB.Spec.Arbiter != nil {
+		members = append(members, ArbiterMember)
+	}
+	if mongoDB.Spec.Hidden != nil {
+		members = append(members, HiddenMember)
+	}
+	return members
+}
+
+// GetName return the name of the statefulSet of the kind of member
+func GetName(mongoDB *db.MongoDB, member string) string {
+	if member == DataMember {
+		return mongoDB.Name
+	}
+	return mongoDB.Name + "-" + member
+}
+
+// GetHeadlessName return the name of the headless service giving the network identity of the members
+func GetHeadlessName(mongoDB *db.MongoDB, member string) string {
+	return GetName(mongoDB, member) + "-headless"
+}
+
+// GetHost return the replica set host of the member pod
+func GetHost(mongoDB *db.MongoDB, member string, index int) string {
+	return fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local:%d",
+		GetName(mongoDB, member), index, GetHeadlessName(mongoDB, member), mongoDB.Namespace, MongoContainerPort)
+}
+
+// GetLabels return the labels of the pods of the kind of member
+func GetLabels(mongoDB *db.MongoDB, member string) map[string]string {
+	if member == DataMember {
+		return util.LabelsForMongoDB(mongoDB.Name)
+	}
+	return util.LabelsForMember(mongoDB.Name, member)
+}
+
+// GetReplicas return the number of pods of the kind of member, none while the instance is hibernated
+func GetReplicas(mongoDB *db.MongoDB, member string) *int32 {
+	var replicas *int32
+	if meta.IsStatusConditionTrue(mongoDB.Status.Conditions, db.ConditionHibernated) {
+		return new(int32)
+	}
+	switch member {
+	case DataMember:
+		replicas = mongoDB.Spec.Replicas
+	case ArbiterMember:
+		if mongoDB.Spec.Arbiter != nil {
+			replicas = mongoDB.Spec.Arbiter.Replicas
+		}
+	case HiddenMember:
+		if mongoDB.Spec.Hidden != nil {
+			replicas = mongoDB.Spec.Hidden.Replicas
+		}
+	}
+	if replicas == nil {
+		replicas = new(int32)
+	}
+	return replicas
+}
+
+// getChecksum return the hash of the fields rendered in the pod template of the member.
+// Only the fields read by the containers, the volumes and the setup scripts are hashed so that scaling,
+// reconfiguring, expanding the volumes or changing the resources handled apart (termination, disruption
+// budget, monitoring resources, service annotations) does not trigger a rolling update.
+// The images and the pod settings are resolved from the configuration so that a reloaded configuration rolls the pods
+func getChecksum(mongoDB *db.MongoDB, member string) string {
+	spec := mongoDB.Spec
+	var exporter *k8sdbv1alpha1.MonitoringConfig
+	if member == DataMember && spec.Monitoring.IsEnabled() {
+		exporter = &k8sdbv1alpha1.MonitoringConfig{
+			Image:      spec.Monitoring.Image,
+			Collectors: spec.Monitoring.Collectors,
+			ExtraArgs:  spec.Monitoring.ExtraArgs,
+			Resources:  spec.Monitoring.Resources,
+		}
+	}
+	var resources *corev1.ResourceRequirements
+	switch {
+	case member == ArbiterMember && spec.Arbiter != nil:
+		resources = spec.Arbiter.Resources
+	case member == HiddenMember && spec.Hidden != nil:
+		resources = spec.Hidden.Resources
+	}
+	rendered := struct {
+		Version          string
+		Flavor           db.Flavor
+		Percona          *db.PerconaSpec
+		PodTemplate      *k8sv1alpha1.PodTemplate
+		TLS              *k8sdbv1alpha1.TLSConfig
+		Exporter         *k8sdbv1alpha1.MonitoringConfig
+		Resources        *corev1.ResourceRequirements
+		ExternalHosts    []string
+		Images           []string
+		ImagePullSecrets []corev1.LocalObjectReference
+		Pod              corev1.PodSpec
+		Container        *corev1.SecurityContext
+	}{
+		spec.Version,
+		spec.GetFlavor(),
+		spec.Percona,
+		spec.PodTemplate,
+		spec.TLS,
+		exporter,
+		resources,
+		getExternalHosts(mongoDB),
+		[]string{getMongoImage(mongoDB), getToolsImage(mongoDB), getMetricsImage(mongoDB)},
+		config.GetImagePullSecrets(spec.Version),
+		corev1.PodSpec{
+			NodeSelector:       util.GetNodeSelector(spec.PodTemplate),
+			ServiceAccountName: util.GetServiceAccount(spec.PodTemplate),
+			SecurityContext:    util.GetSecurityContext(spec.PodTemplate),
+			Affinity:           util.GetAffinity(spec.PodTemplate),
+			Tolerations:        util.GetTolerations(spec.PodTemplate),
+		},
+		util.GetContainerSecurityContext(spec.PodTemplate),
+	}
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return util.AsSha256(rendered)
+	}
+	return util.AsSha256(string(data))
+}
+
+func getPodContainers(ctx context.Context, mongoDB *db.MongoDB, member string) []corev1.Container {
+	containers := []corev1.Container{
+		getContainers(ctx, mongoDB, member),
+	}
+	if member == DataMember && mongoDB.Spec.Monitoring.IsEnabled() {
+		containers = append(containers, getMetricsContainers(ctx, mongoDB))
+	}
+	return containers
+}
+
+func getContainers(ctx context.Context, mongoDB *db.MongoDB, member string) corev1.Container {
 	log := util.GetLog(ctx, mongoDB)
-	log.V(1).Info("get container")
-	nonRoot := true
-	var runUser int64 = 1001
+	log.V(1).Info("get container", "member", member)
+	f := getFlavor(mongoDB)
 	container := corev1.Container{
 		Name:  "mongodb",
 		Image: getMongoImage(mongoDB),
-		//Command: []string{
-		//	"/scripts/setup.sh",
-		//},
+		Command: []string{
+			"/scripts/" + getScriptKey(member),
+		},
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          MongoName,
 				ContainerPort: MongoContainerPort,
 			},
 		},
-		Env: getEnv(mongoDB),
-		SecurityContext: &corev1.SecurityContext{
-			RunAsNonRoot: &nonRoot,
-			RunAsUser:    &runUser,
-		},
-		VolumeMounts: []corev1.VolumeMount{
+		Env:             f.getEnv(mongoDB, member),
+		SecurityContext: util.GetContainerSecurityContext(mongoDB.Spec.PodTemplate),
+		VolumeMounts: append([]corev1.VolumeMount{
 			{
-				Name:      "datadir",
-				MountPath: "/bitnami/mongodb",
+				Name:      DataVolumeName,
+				MountPath: f.getDataPath(),
 			},
-			//{
-			//	Name:      "scripts",
-			//	MountPath: "/scripts/setup.sh",
-			//	SubPath:   "setup.sh",
-			//},
-		},
-		LivenessProbe:  getMongoProbe(30),
-		ReadinessProbe: getMongoProbe(5),
+		}, append(f.getVolumeMounts(mongoDB, member), AddVolumeMountTLS(mongoDB.Spec.TLS)...)...),
+		StartupProbe:   getStartupProbe(mongoDB),
+		LivenessProbe:  getLivenessProbe(mongoDB),
+		ReadinessProbe: getReadinessProbe(mongoDB),
+	}
+	if resources := getResources(mongoDB, member); resources != nil {
+		container.Resources = *resources
 	}
 	return container
 }
 
-func getMongoProbe(initDelay int32) *corev1.Probe {
-	return &corev1.Probe{
-		Handler: corev1.Handler{
-			Exec: &corev1.ExecAction{
-				Command: []string{
-					"mongo",
-					"--eval",
-					"db.adminCommand('ping')",
-				},
-			},
-		},
-		FailureThreshold:    6,
-		InitialDelaySeconds: initDelay,
-		PeriodSeconds:       10,
-		SuccessThreshold:    1,
-		TimeoutSeconds:      5,
+// getScriptKey return the setup script of the kind of member
+func getScriptKey(member string) string {
+	switch member {
+	case ArbiterMember:
+		return configmap.SetupArbiterKey
+	case HiddenMember:
+		return configmap.SetupHiddenKey
 	}
+	return configmap.SetupKey
 }
 
-func getMongoImage(mongoDB *db.MongoDB) string {
-	return fmt.Sprintf("%s:%s-debian-10", config.GetImage(MongoName), mongoDB.Spec.Version)
+// getResources return the resources of the mongodb container of the arbiter and hidden members
+func getResources(mongoDB *db.MongoDB, member string) *corev1.ResourceRequirements {
+	switch {
+	case member == ArbiterMember && mongoDB.Spec.Arbiter != nil:
+		return mongoDB.Spec.Arbiter.Resources
+	case member == HiddenMember && mongoDB.Spec.Hidden != nil:
+		return mongoDB.Spec.Hidden.Resources
+	}
+	return nil
 }
-func getEnv(mongoDB *db.MongoDB) []corev1.EnvVar {
-	env := []corev1.EnvVar{
-		{
-			Name:  "BITNAMI_DEBUG",
-			Value: "false",
-		},
-		{
-			Name:  "MY_POD_NAME",
-			Value: mongoDB.Namespace,
-		},
-		{
-			Name:  "K8S_SERVICE_NAME",
-			Value: mongoDB.Name,
-		},
-		{
-			Name:  "MONGODB_INITIAL_PRIMARY_HOST",
-			Value: getFullname(mongoDB),
-		},
-		{
-			Name:  "MONGODB_REPLICA_SET_NAME",
-			Value: "rs0",
-		},
+
+// getVolumeClaimTemplates return the data volume claim, arbiters hold no data and use an empty dir.
+// The data-bearing members are restored from the data source, hidden members run an initial sync
+func getVolumeClaimTemplates(mongoDB *db.MongoDB, member string) []corev1.PersistentVolumeClaim {
+	storage := *mongoDB.Spec.Storage.DeepCopy()
+	switch member {
+	case ArbiterMember:
+		return nil
+	case HiddenMember:
+		if mongoDB.Spec.Hidden != nil && mongoDB.Spec.Hidden.Storage != nil {
+			storage = *mongoDB.Spec.Hidden.Storage
+		}
+	case DataMember:
+		if mongoDB.Spec.DataSource != nil {
+			storage.DataSource = mongoDB.Spec.DataSource.DeepCopy()
+		}
+	}
+	return []corev1.PersistentVolumeClaim{
 		{
-			Name: "MONGODB_ROOT_PASSWORD",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: mongoDB.Name,
-					},
-					Key: MongoRootPasswordKey,
-				},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: DataVolumeName,
 			},
-		},
-		{
-			Name:  "ALLOW_EMPTY_PASSWORD",
-			Value: "no",
-		},
-		{
-			Name:  "MONGODB_SYSTEM_LOG_VERBOSITY",
-			Value: "0",
-		},
-		{
-			Name:  "MONGODB_DISABLE_SYSTEM_LOG",
-			Value: "no",
-		},
-		{
-			Name:  "MONGODB_DISABLE_JAVASCRIPT",
-			Value: "no",
-		},
-		{
-			Name:  "MONGODB_ENABLE_IPV6",
-			Value: "no",
-		},
-		{
-			Name:  "MONGODB_ENABLE_DIRECTORY_PER_DB",
-			Value: "no",
+			Spec: storage,
 		},
 	}
-	if tls := AddEnvTLS(mongoDB.Spec.TLS); len(tls) > 0 {
-		env = append(env, tls...)
+}
+
+// getMongoImage return the image of the version from the catalog, or the image of the flavor
+// when the version is not in the catalog
+func getMongoImage(mongoDB *db.MongoDB) string {
+	if version, ok := config.GetVersion(mongoDB.Spec.Version); ok {
+		return string(version.MongoDB)
 	}
-	return env
+	return getFlavor(mongoDB).getImage(mongoDB.Spec.Version)
+}
+
+// getToolsImage return the image of the init containers of the version
+func getToolsImage(mongoDB *db.MongoDB) string {
+	if version, ok := config.GetVersion(mongoDB.Spec.Version); ok && version.Tools != "" {
+		return string(version.Tools)
+	}
+	return config.GetImage("tls")
 }
 
 func getFullname(mongoDB *db.MongoDB) string {
-	return fmt.Sprintf("%s-0.%s.%s.svc.cluster.local", mongoDB.Name, mongoDB.Name, mongoDB.Namespace)
+	return fmt.Sprintf("%s-0.%s.%s.svc.cluster.local", mongoDB.Name, GetHeadlessName(mongoDB, DataMember), mongoDB.Namespace)
 }
 
 func getInitContainers(mongoDB *db.MongoDB) []corev1.Container {
@@ -245,11 +347,17 @@ func getInitContainers(mongoDB *db.MongoDB) []corev1.Container {
 	if tls := AddVolumeMountTLS(mongoDB.Spec.TLS); len(tls) > 0 {
 		vm = append(vm, tls...)
 	}
+	vm = append(vm, corev1.VolumeMount{
+		Name:      EmptyDirVolumeName,
+		MountPath: "/tmp",
+		SubPath:   "tmp-dir",
+	})
 	init = append(init, corev1.Container{
 
 		Name:            "generate-tls-certs",
-		Image:           config.GetImage("tls"),
-		ImagePullPolicy: "Always",
+		Image:           getToolsImage(mongoDB),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		SecurityContext: util.GetContainerSecurityContext(mongoDB.Spec.PodTemplate),
 		Env: []corev1.EnvVar{
 			{
 				Name:  "MY_POD_NAMESPACE",
@@ -260,8 +368,7 @@ func getInitContainers(mongoDB *db.MongoDB) []corev1.Container {
 		Command: []string{
 			"sh",
 			"-c",
-			`|
-/bin/bash <<'EOF'
+			`/bin/bash <<'EOF'
 my_hostname=$(hostname)
 svc=$(echo -n "$my_hostname" | sed s/-[0-9]*$//)-headless
 cp /certs/CAs/* /certs/
@@ -280,12 +387,7 @@ DNS.2 = $my_hostname
 DNS.3 = $my_hostname.$svc.$MY_POD_NAMESPACE.svc.cluster.local
 DNS.4 = localhost
 DNS.5 = 127.0.0.1
-{{- if .Values.externalAccess.service.loadBalancerIPs }}
-{{- range $key, $val := .Values.externalAccess.service.loadBalancerIPs }}
-IP.{{ $key }} = {{ $val | quote }}
-{{- end }}
-{{- end }}
-EOL
+` + getExternalAltNames(mongoDB) + `EOL
 
 export RANDFILE=/certs/.rnd && openssl genrsa -out /certs/mongo.key 2048
 #CreateUpdate the client/server certificate
@@ -306,6 +408,69 @@ EOF
 	return init
 }
 
+// getVolumes return the volumes of the mongodb pod
+func getVolumes(mongoDB *db.MongoDB, member string) []corev1.Volume {
+	v := []corev1.Volume{
+		{
+			Name: EmptyDirVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		configmap.GetVolume(ScriptsVolumeName, corev1.LocalObjectReference{Name: configmap.GetScriptsName(mongoDB)}),
+	}
+	if member == ArbiterMember {
+		v = append(v, corev1.Volume{
+			Name: DataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+	v = append(v, getFlavor(mongoDB).getVolumes(mongoDB)...)
+	return append(v, AddVolumeTLS(mongoDB.Spec.TLS)...)
+}
+
+// getExternalAltNames return the openssl subjectAltName entries of the external addresses
+func getExternalAltNames(mongoDB *db.MongoDB) string {
+	var altNames strings.Builder
+	dnsIndex := 6
+	ipIndex := 1
+	for _, host := range getExternalHosts(mongoDB) {
+		if net.ParseIP(host) != nil {
+			altNames.WriteString(fmt.Sprintf("IP.%d = %s\n", ipIndex, host))
+			ipIndex++
+			continue
+		}
+		altNames.WriteString(fmt.Sprintf("DNS.%d = %s\n", dnsIndex, host))
+		dnsIndex++
+	}
+	return altNames.String()
+}
+
+// getExternalHosts return the host part of the discovered and requested external addresses
+func getExternalHosts(mongoDB *db.MongoDB) []string {
+	var hosts []string
+	if mongoDB.Spec.ExternalAccess == nil {
+		return hosts
+	}
+	candidates := append([]string{}, mongoDB.Spec.ExternalAccess.Hosts...)
+	candidates = append(candidates, mongoDB.Spec.ExternalAccess.LoadBalancerIPs...)
+	for _, endpoint := range mongoDB.Status.ExternalEndpoints {
+		candidates = append(candidates, endpoint.Address)
+	}
+	for _, candidate := range candidates {
+		host := candidate
+		if h, _, err := net.SplitHostPort(candidate); err == nil {
+			host = h
+		}
+		if host != "" && !util.StringInArray(host, hosts) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 //func AddVolumes(mongoDB *db.MongoDB) []corev1.Volume {
 //	var v []corev1.Volume
 //	v = append(v, configmap.GetVolume("scripts", util.GetLocalObjectReference(mongoDB.Name+"-scripts")))
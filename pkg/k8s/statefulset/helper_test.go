@@ -0,0 +1,108 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	k8sdbv1alpha1 "github.com/w6d-io/mongodb/apis/k8sdb/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Helper", func() {
+	var mongoDB *db.MongoDB
+	BeforeEach(func() {
+		enabled := true
+		mongoDB = &db.MongoDB{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: db.MongoDBSpec{
+				Version:    "4.4",
+				Monitoring: &k8sdbv1alpha1.MonitoringConfig{Enabled: &enabled},
+				Arbiter:    &db.ArbiterSpec{},
+			},
+		}
+	})
+	Context("checksum", func() {
+		for _, tc := range []struct {
+			name   string
+			change func(*db.MongoDB)
+		}{
+			{"termination policy", func(m *db.MongoDB) {
+				m.Spec.TerminationPolicy = db.TerminationPolicyDoNotTerminate
+			}},
+			{"final snapshot", func(m *db.MongoDB) {
+				m.Spec.FinalSnapshot = &db.FinalSnapshotSpec{}
+			}},
+			{"pod disruption budget", func(m *db.MongoDB) {
+				m.Spec.PodDisruptionBudget = &db.PodDisruptionBudgetSpec{}
+			}},
+			{"prometheus rule", func(m *db.MongoDB) {
+				m.Spec.Monitoring.PrometheusRule = &k8sdbv1alpha1.PrometheusRuleConfig{}
+			}},
+			{"service monitor", func(m *db.MongoDB) {
+				m.Spec.Monitoring.ServiceMonitor = &k8sdbv1alpha1.ServiceMonitorConfig{}
+			}},
+			{"external access annotations", func(m *db.MongoDB) {
+				m.Spec.ExternalAccess = &db.ExternalAccess{Annotations: map[string]string{"a": "b"}}
+			}},
+			{"replicas", func(m *db.MongoDB) {
+				replicas := int32(3)
+				m.Spec.Replicas = &replicas
+			}},
+			{"arbiter resources", func(m *db.MongoDB) {
+				m.Spec.Arbiter.Resources = &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				}
+			}},
+		} {
+			tc := tc
+			It("keeps the checksum when the "+tc.name+" changes", func() {
+				checksum := getChecksum(mongoDB, DataMember)
+				tc.change(mongoDB)
+				Expect(getChecksum(mongoDB, DataMember)).To(Equal(checksum))
+			})
+		}
+		for _, tc := range []struct {
+			name   string
+			member string
+			change func(*db.MongoDB)
+		}{
+			{"version", DataMember, func(m *db.MongoDB) {
+				m.Spec.Version = "5.0"
+			}},
+			{"exporter image", DataMember, func(m *db.MongoDB) {
+				m.Spec.Monitoring.Image = "exporter:1"
+			}},
+			{"arbiter resources", ArbiterMember, func(m *db.MongoDB) {
+				m.Spec.Arbiter.Resources = &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				}
+			}},
+		} {
+			tc := tc
+			It("changes the checksum when the "+tc.name+" changes", func() {
+				checksum := getChecksum(mongoDB, tc.member)
+				tc.change(mongoDB)
+				Expect(getChecksum(mongoDB, tc.member)).ToNot(Equal(checksum))
+			})
+		}
+	})
+})
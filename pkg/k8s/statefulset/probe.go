@@ -0,0 +1,90 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"github.com/w6d-io/mongodb/internal/util"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// pingScript checks that mongod answers
+	pingScript string = "db.adminCommand('ping')"
+	// stateScript checks that the member is the primary, a secondary or an arbiter of the replica set,
+	// the members starting up, recovering, running an initial sync or removed are not ready
+	stateScript string = "var r = db.adminCommand({isMaster: 1}); if (!(r.ismaster || r.secondary || r.arbiterOnly)) { quit(1) }"
+)
+
+// getStartupProbe return the probe holding back the liveness and readiness probes until mongod answers,
+// long recoveries of the journal are not killed by the liveness probe
+func getStartupProbe(mongoDB *db.MongoDB) *corev1.Probe {
+	probe := getMongoProbe(mongoDB, pingScript)
+	probe.FailureThreshold = StartupFailureThreshold
+	return probe
+}
+
+// getLivenessProbe return the probe restarting the members whose mongod stopped answering
+func getLivenessProbe(mongoDB *db.MongoDB) *corev1.Probe {
+	return getMongoProbe(mongoDB, pingScript)
+}
+
+// getReadinessProbe return the probe reporting the members holding their replica set state as ready
+func getReadinessProbe(mongoDB *db.MongoDB) *corev1.Probe {
+	return getMongoProbe(mongoDB, stateScript)
+}
+
+func getMongoProbe(mongoDB *db.MongoDB, script string) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: getShellCommand(mongoDB, script),
+			},
+		},
+		FailureThreshold: 6,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		TimeoutSeconds:   5,
+	}
+}
+
+// getShellCommand return the command evaluating the script in the shell shipped with the version
+func getShellCommand(mongoDB *db.MongoDB, script string) []string {
+	command := append([]string{GetShell(mongoDB), "--quiet"}, getShellFlags(mongoDB)...)
+	return append(command, "--eval", script)
+}
+
+// getShellFlags return the flags of the shell connecting to the local mongod
+func getShellFlags(mongoDB *db.MongoDB) []string {
+	if mongoDB.Spec.TLS == nil {
+		return nil
+	}
+	if GetShell(mongoDB) == LegacyShell {
+		return []string{"--ssl", "--sslCAFile=/certs/mongodb-ca-cert", "--sslPEMKeyFile=/certs/mongodb.pem"}
+	}
+	return []string{"--tls", "--tlsCAFile=/certs/mongodb-ca-cert", "--tlsCertificateKeyFile=/certs/mongodb.pem"}
+}
+
+// GetShell return the MongoDB shell of the version, the legacy shell is no longer shipped from 6.0.
+// The versions that cannot be parsed are expected to be recent ones
+func GetShell(mongoDB *db.MongoDB) string {
+	if major := util.GetMajorVersion(mongoDB.Spec.Version); major > 0 && major < 6 {
+		return LegacyShell
+	}
+	return Shell
+}
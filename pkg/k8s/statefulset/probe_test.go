@@ -0,0 +1,81 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	k8sdbv1alpha1 "github.com/w6d-io/mongodb/apis/k8sdb/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Probe", func() {
+	var mongoDB *db.MongoDB
+	BeforeEach(func() {
+		mongoDB = &db.MongoDB{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       db.MongoDBSpec{Version: "4.4"},
+		}
+	})
+	Context("shell", func() {
+		for _, tc := range []struct {
+			version string
+			shell   string
+		}{
+			{"4.4", LegacyShell},
+			{"5.0.14", LegacyShell},
+			{"6.0", Shell},
+			{"7.0.2", Shell},
+			{"", Shell},
+			{"latest", Shell},
+		} {
+			tc := tc
+			It("returns "+tc.shell+" for the version '"+tc.version+"'", func() {
+				mongoDB.Spec.Version = tc.version
+				Expect(GetShell(mongoDB)).To(Equal(tc.shell))
+			})
+		}
+	})
+	Context("probes", func() {
+		It("pings mongod on startup and liveness", func() {
+			startup := getStartupProbe(mongoDB)
+			Expect(startup.Exec.Command).To(Equal([]string{LegacyShell, "--quiet", "--eval", pingScript}))
+			Expect(startup.FailureThreshold).To(Equal(StartupFailureThreshold))
+			liveness := getLivenessProbe(mongoDB)
+			Expect(liveness.Exec.Command).To(Equal(startup.Exec.Command))
+			Expect(liveness.FailureThreshold).To(Equal(int32(6)))
+		})
+		It("checks the replica set state on readiness", func() {
+			mongoDB.Spec.Version = "6.0"
+			Expect(getReadinessProbe(mongoDB).Exec.Command).To(Equal([]string{Shell, "--quiet", "--eval", stateScript}))
+		})
+		It("connects with the TLS flags of the shell", func() {
+			mongoDB.Spec.TLS = &k8sdbv1alpha1.TLSConfig{}
+			Expect(getLivenessProbe(mongoDB).Exec.Command).To(Equal([]string{
+				LegacyShell, "--quiet", "--ssl", "--sslCAFile=/certs/mongodb-ca-cert", "--sslPEMKeyFile=/certs/mongodb.pem",
+				"--eval", pingScript,
+			}))
+			mongoDB.Spec.Version = "6.0"
+			Expect(getLivenessProbe(mongoDB).Exec.Command).To(Equal([]string{
+				Shell, "--quiet", "--tls", "--tlsCAFile=/certs/mongodb-ca-cert", "--tlsCertificateKeyFile=/certs/mongodb.pem",
+				"--eval", pingScript,
+			}))
+		})
+	})
+})
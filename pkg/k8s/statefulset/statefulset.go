@@ -25,9 +25,14 @@ import (
 	"github.com/w6d-io/mongodb/pkg/k8s/secret"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	db "github.com/w6d-io/mongodb/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sretry "k8s.io/client-go/util/retry"
 )
 
 func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme, mongoDB *db.MongoDB) error {
@@ -47,15 +52,34 @@ func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme,
 	); err != nil {
 		return err
 	}
-	sts := getStatefulSetMongoDB(ctx, r, scheme, mongoDB)
-	if sts == nil {
-		log.Error(nil, "get configmap return nil")
-		return &Error{Cause: nil, Detail: "get configmap return nil"}
+	members := GetMembers(mongoDB)
+	for _, member := range Members {
+		if !util.StringInArray(member, members) {
+			if err := deleteMember(ctx, r, mongoDB, member); err != nil {
+				return err
+			}
+			continue
+		}
+		sts := getStatefulSetMongoDB(ctx, scheme, mongoDB, member)
+		if sts == nil {
+			log.Error(nil, "get statefulSet return nil")
+			return &Error{Cause: nil, Detail: "get statefulSet return nil"}
+		}
+		if err := createUpdate(ctx, r, sts); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+func createUpdate(ctx context.Context, r client.Client, sts *appsv1.StatefulSet) error {
+	log := util.GetLog(ctx, sts)
 	log.V(1).Info("create statefulSet")
 	err := r.Create(ctx, sts)
-	if err != nil && !errors.IsAlreadyExists(err) {
+	if err != nil && errors.IsAlreadyExists(err) {
+		return update(ctx, r, sts)
+	}
+	if err != nil {
 		log.Error(err, "create statefulSet failed")
 		return &Error{
 			Cause:  err,
@@ -65,6 +89,80 @@ func CreateUpdate(ctx context.Context, r client.Client, scheme *runtime.Scheme,
 	return nil
 }
 
+// deleteMember deletes the statefulSet of the kind of member removed from the spec
+func deleteMember(ctx context.Context, r client.Client, mongoDB *db.MongoDB, member string) error {
+	log := util.GetLog(ctx, mongoDB).WithValues("member", member)
+	sts := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: GetName(mongoDB, member), Namespace: mongoDB.Namespace}, sts)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		log.Error(err, "get statefulSet failed")
+		return &Error{Cause: err, Detail: "get statefulSet failed"}
+	}
+	if !metav1.IsControlledBy(sts, mongoDB) {
+		return nil
+	}
+	log.V(1).Info("delete statefulSet")
+	if err = r.Delete(ctx, sts); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "delete statefulSet failed")
+		return &Error{Cause: err, Detail: "delete statefulSet failed"}
+	}
+	return nil
+}
+
+// update replaces the pod template of the existing statefulSet when the checksum changed
+// and scales it to the requested replicas. The service name is immutable, a statefulSet created
+// before the headless services is deleted without its pods to be recreated on the next reconcile,
+// its pods are then adopted and rolled to the new template
+func update(ctx context.Context, r client.Client, sts *appsv1.StatefulSet) error {
+	log := util.GetLog(ctx, sts)
+	err := k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
+		current := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, util.GetTypesNamespaceNamed(ctx, sts), current); err != nil {
+			return err
+		}
+		if current.DeletionTimestamp != nil {
+			return nil
+		}
+		if current.Spec.ServiceName != sts.Spec.ServiceName {
+			log.Info("delete statefulSet orphaning its pods to update the service name",
+				"current", current.Spec.ServiceName, "wanted", sts.Spec.ServiceName)
+			return client.IgnoreNotFound(r.Delete(ctx, current, client.PropagationPolicy(metav1.DeletePropagationOrphan)))
+		}
+		template := current.Spec.Template.Annotations[ChecksumAnnotation] != sts.Spec.Template.Annotations[ChecksumAnnotation]
+		scale := current.Spec.Replicas == nil || *current.Spec.Replicas != *sts.Spec.Replicas
+		if !template && !scale {
+			return nil
+		}
+		log.V(1).Info("update statefulSet", "template", template, "scale", scale)
+		current.Spec.Template = sts.Spec.Template
+		if scale {
+			current.Spec.Replicas = sts.Spec.Replicas
+		}
+		return r.Update(ctx, current)
+	})
+	if err != nil {
+		log.Error(err, "update statefulSet failed")
+		return &Error{
+			Cause:  err,
+			Detail: "update statefulSet failed",
+		}
+	}
+	return nil
+}
+
+// ListClaims return the persistent volume claims of the members of the instance
+func ListClaims(ctx context.Context, r client.Client, mongoDB *db.MongoDB) ([]corev1.PersistentVolumeClaim, error) {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcs, client.InNamespace(mongoDB.Namespace),
+		client.MatchingLabels{"db.w6d.io/release": mongoDB.Name}); err != nil {
+		return nil, &Error{Cause: err, Detail: "list persistent volume claims failed"}
+	}
+	return pvcs.Items, nil
+}
+
 func (e *Error) Error() string {
 	if e.Cause == nil {
 		return e.Detail
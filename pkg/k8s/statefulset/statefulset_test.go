@@ -0,0 +1,71 @@
+/*
+Copyright 2021 WILDCARD SA.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 19/10/2026
+*/
+package statefulset
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("StatefulSet", func() {
+	var ctx context.Context
+	var sts *appsv1.StatefulSet
+	BeforeEach(func() {
+		ctx = context.Background()
+		replicas := int32(1)
+		sts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:    &replicas,
+				ServiceName: "test-headless",
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ChecksumAnnotation: "new"}},
+				},
+			},
+		}
+	})
+	Context("update", func() {
+		It("deletes the statefulSet created with another service name", func() {
+			current := sts.DeepCopy()
+			current.Spec.ServiceName = "test"
+			r := fake.NewClientBuilder().WithObjects(current).Build()
+			Expect(update(ctx, r, sts)).To(Succeed())
+			err := r.Get(ctx, types.NamespacedName{Name: "test", Namespace: "default"}, &appsv1.StatefulSet{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+		It("replaces the pod template when the checksum changed", func() {
+			current := sts.DeepCopy()
+			current.Spec.Template.Annotations[ChecksumAnnotation] = "old"
+			r := fake.NewClientBuilder().WithObjects(current).Build()
+			Expect(update(ctx, r, sts)).To(Succeed())
+			updated := &appsv1.StatefulSet{}
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(sts), updated)).To(Succeed())
+			Expect(updated.Spec.Template.Annotations).To(HaveKeyWithValue(ChecksumAnnotation, "new"))
+			Expect(updated.Spec.ServiceName).To(Equal("test-headless"))
+		})
+	})
+})
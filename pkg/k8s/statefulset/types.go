@@ -21,8 +21,50 @@ const (
 	MongoRootPasswordKey      string = "mongodb-root-password"
 	MongoContainerPort        int32  = 27017
 	MongoContainerMetricsPort int32  = 9216
+	EmptyDirVolumeName        string = "empty-dir"
+	ChecksumAnnotation        string = "checksum/configuration"
+	DataVolumeName            string = "datadir"
+	ScriptsVolumeName         string = "scripts"
+
+	// RoleLabel is the pod label holding the replica set role of the data-bearing members
+	RoleLabel     string = "db.w6d.io/role"
+	RolePrimary   string = "primary"
+	RoleSecondary string = "secondary"
+
+	// DataMember is the kind of the data-bearing members
+	DataMember string = ""
+	// ArbiterMember is the kind of the arbiter members
+	ArbiterMember string = "arbiter"
+	// HiddenMember is the kind of the hidden members
+	HiddenMember string = "hidden"
+
+	// Shell is the MongoDB shell shipped with the images from 5.0
+	Shell string = "mongosh"
+	// LegacyShell is the MongoDB shell shipped with the images up to 5.0
+	LegacyShell string = "mongo"
+	// OfficialImageKey is the key of the official image in the configuration
+	OfficialImageKey string = "mongo"
+	// OfficialImage is the official image used when missing from the configuration
+	OfficialImage string = "mongo"
+	// PerconaImageKey is the key of the Percona Server for MongoDB image in the configuration
+	PerconaImageKey string = "percona"
+	// PerconaImage is the Percona Server for MongoDB image used when missing from the configuration
+	PerconaImage string = "percona/percona-server-mongodb"
+
+	// EncryptionKeyVolumeName is the volume of the master key of the encryption at rest
+	EncryptionKeyVolumeName string = "encryption-key"
+	// EncryptionKeyPath is the mount path of the master key
+	EncryptionKeyPath string = "/etc/mongodb-encryption"
+	// EncryptionKeyName is the file name of the master key
+	EncryptionKeyName string = "encryption-key"
+
+	// StartupFailureThreshold is the number of failed startup probes, every ten seconds, before mongod is restarted
+	StartupFailureThreshold int32 = 90
 )
 
+// Members are the kinds of member managed by their own statefulSet
+var Members = []string{DataMember, ArbiterMember, HiddenMember}
+
 type Error struct {
 	Cause  error
 	Detail string